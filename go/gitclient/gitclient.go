@@ -7,41 +7,170 @@ import (
 type GitClient interface {
 	GetApiRateUsed() int
 	GetApiRateRemaining() int
+	GetPullRequestsFetched() int
+	GetApiCallsByOperation() map[string]int
+	GetAuthenticatedUser() (string, error)
+	GetRateLimitStatus() (*RateLimitStatus, error)
+	GetOrgRepos(org string, filter RepoFilter) ([]string, error)
+	GetTeamMembers(org string, teamSlug string) ([]string, error)
 	GetPullRequests(owner string, repo string, dateFrom, dateTo time.Time) ([]*PullRequest, error)
+	GetPullRequestsUpdated(owner string, repo string, dateFrom, dateTo time.Time) ([]*PullRequest, error)
 	GetComments(owner string, repo string, prNumber int) ([]*PullRequestComment, error)
+	GetIssueComments(owner string, repo string, prNumber int) ([]*IssueComment, error)
 	GetReviews(owner string, repo string, prNumber int) ([]*PullRequestReview, error)
-	GetCommits(owner string, repo string, prNumber int, firstCommentTime time.Time, includeFiles bool) ([]*RepositoryCommit, []error)
+	GetReviewEdits(owner string, repo string, prNumber int) (map[int64]time.Time, error)
+	GetReviewThreads(owner string, repo string, prNumber int) ([]*ReviewThread, error)
+	GetCommits(owner string, repo string, prNumber int, firstCommentTime time.Time, paths []string) ([]*RepositoryCommit, []error)
+	GetTimelineEvents(owner string, repo string, prNumber int) ([]*TimelineEvent, error)
+	GetLineStats(owner string, repo string, prNumber int) (*LineStats, error)
+	GetChangedFiles(owner string, repo string, prNumber int) ([]string, error)
+	GetChangedFilePatches(owner string, repo string, prNumber int) ([]*RepositoryCommitFile, error)
+	GetCommentReactions(owner string, repo string, commentID int64) ([]*Reaction, error)
 }
 
 // Types included in the interface definition.
+type RepoFilter struct {
+	IncludeArchived bool
+	IncludeForks    bool
+}
+
 type PullRequestComment struct {
-	PullRequestReviewID int64
+	ID                  int64
+	PullRequestReviewID int64 // Review the comment belongs to, zero for comments GitHub reports without a review
 	UserID              int64
+	UserLogin           *string
 	Path                *string
-	OriginalPosition    int
+	Body                string
+	AuthorAssociation   string // Relationship of the commenter to the repository, one of the AuthorAssociation constants
+	OriginalLine        *int   // Last commented line in the file as of the commented commit, nil for some outdated comments
+	OriginalStartLine   *int   // First commented line of a multi-line comment, nil for single-line comments
+	Side                string // SideRight for lines of the changed file, SideLeft for lines of the base, empty when unknown
 	CreatedAt           *time.Time
+	PositionUnstable    bool // Set when the branch was force-pushed after the comment, so OriginalLine may no longer match
+	ReactionCount       int
 }
 
+// Comment of the general discussion of a pull request, outside of the diff and the reviews
+type IssueComment struct {
+	ID        int64
+	UserID    int64
+	UserLogin *string
+	Body      string
+	CreatedAt *time.Time
+}
+
+// Sides of the diff a review comment is on
+const (
+	SideLeft  = "LEFT"
+	SideRight = "RIGHT"
+)
+
+type Reaction struct {
+	UserLogin *string
+	Content   string
+}
+
+// Reaction contents that don't acknowledge a comment
+const (
+	ReactionMinusOne = "-1"
+	ReactionConfused = "confused"
+)
+
 type PullRequestReview struct {
 	ID          int64
 	UserID      int64
 	UserLogin   *string
+	UserType    string // Account type of the reviewer, UserTypeBot for GitHub Apps and Actions, empty when unknown
 	SubmittedAt *time.Time
+	State       string
+
+	// Relationship of the reviewer to the repository, one of the AuthorAssociation constants, empty when unknown
+	AuthorAssociation string
+}
+
+// Conversation on the diff started by a review comment
+type ReviewThread struct {
+	ReviewID int64 // Review of the comment that started the thread
+	Resolved bool
 }
 
+// Account type of the GitHub Apps and Actions, the others are "User"
+const UserTypeBot = "Bot"
+
 type RepositoryCommit struct {
 	CreatedAt *time.Time
+	AuthorID  *int64 // GitHub user of the commit author, nil when the author email isn't linked to an account
+	Message   string // Full commit message, including trailers such as Co-authored-by
 	Files     []*RepositoryCommitFile
 }
 
 type RepositoryCommitFile struct {
-	Filename *string
-	Patch    *string
+	Filename         *string
+	PreviousFilename *string // Name before the commit renamed the file, nil when it wasn't renamed
+	Patch            *string
 }
 
 type PullRequest struct {
-	Number    int
-	Title     *string
-	UserLogin *string
-	CreatedAt *time.Time
+	Number      int
+	Title       *string
+	UserLogin   *string
+	UserID      *int64 // GitHub user of the author, nil when unknown
+	CreatedAt   *time.Time
+	Labels      []string
+	MergedState string     // One of the MergedState constants, empty when unknown
+	MergedAt    *time.Time // When the pull request was merged, nil when it wasn't or it is unknown
+	HeadSHA     *string    // Current head commit, nil when unknown
+	HeadRef     *string    // Branch the changes are pulled from, nil when unknown
+	BaseSHA     *string    // Commit of the base branch the pull request is compared to, nil when unknown
+}
+
+// Whether a pull request is still open, was merged, or was closed without merging
+const (
+	MergedStateOpen           = "open"
+	MergedStateMerged         = "merged"
+	MergedStateClosedUnmerged = "closed-unmerged"
+)
+
+// Event types reported by the issue timeline
+const (
+	TimelineEventForcePushed     = "head_ref_force_pushed"
+	TimelineEventReviewRequested = "review_requested"
+	TimelineEventReadyForReview  = "ready_for_review"
+)
+
+type LineStats struct {
+	Additions    int
+	Deletions    int
+	ChangedFiles int
+}
+
+// Relationships of the authors of reviews and comments to the repository
+const (
+	AuthorAssociationOwner                = "OWNER"
+	AuthorAssociationMember               = "MEMBER"
+	AuthorAssociationCollaborator         = "COLLABORATOR"
+	AuthorAssociationContributor          = "CONTRIBUTOR"
+	AuthorAssociationFirstTimeContributor = "FIRST_TIME_CONTRIBUTOR"
+	AuthorAssociationFirstTimer           = "FIRST_TIMER"
+	AuthorAssociationMannequin            = "MANNEQUIN"
+	AuthorAssociationNone                 = "NONE"
+)
+
+// Review states reported by GitHub
+const (
+	ReviewStateApproved         = "APPROVED"
+	ReviewStateChangesRequested = "CHANGES_REQUESTED"
+	ReviewStateCommented        = "COMMENTED"
+)
+
+type TimelineEvent struct {
+	Event         string
+	CreatedAt     *time.Time
+	ReviewerLogin *string // Requested reviewer of review_requested events
+}
+
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
 }
@@ -2,88 +2,511 @@ package gitclient
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/go-github/v50/github"
 	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
 type GitHubClient struct {
 	client           *github.Client
+	limiter          *rate.Limiter // Shared by all calls to keep the request rate in bounds, nil when unlimited
+	maxRPS           float64       // Request rate the limiter never exceeds, zero to only follow the rate limit
+	mu               sync.Mutex    // Guards the rate counters, which calls running in parallel update
 	apiRateUsed      int
 	apiRateRemaining int
+	prsFetched       int            // Pull requests whose reviews were fetched, to relate the API calls to
+	apiCalls         map[string]int // API calls by operation, nil until the first call
+	login            string         // Authenticated user, empty until it's known
+	useSearch        bool
+	minRemaining     int
+	waitForReset     bool
+	order            string
+	maxPages         int
+	sleep            func(time.Duration) // Waits out rate limits, time.Sleep when nil
 }
 
 func (g *GitHubClient) GetApiRateUsed() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	return g.apiRateUsed
 }
 
 func (g *GitHubClient) GetApiRateRemaining() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 	return g.apiRateRemaining
 }
 
+// Returns the number of pull requests whose reviews were fetched so far
+func (g *GitHubClient) GetPullRequestsFetched() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.prsFetched
+}
+
+// Operations the API calls are counted by, to find where the calls of a slow run go
+const (
+	OperationUser          = "user"
+	OperationRepos         = "repos"
+	OperationTeamMembers   = "team members"
+	OperationPullRequests  = "pull requests"
+	OperationReviews       = "reviews"
+	OperationReviewEdits   = "review edits"
+	OperationReviewThreads = "review threads"
+	OperationComments      = "comments"
+	OperationIssueComments = "issue comments"
+	OperationCommits       = "commits"
+	OperationCommitDetails = "commit details"
+	OperationLineStats     = "line stats"
+	OperationChangedFiles  = "changed files"
+	OperationReactions     = "reactions"
+	OperationTimeline      = "timeline"
+)
+
+// Returns the API calls made so far by operation, one of the Operation constants. Their sum is GetApiRateUsed.
+func (g *GitHubClient) GetApiCallsByOperation() map[string]int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	result := make(map[string]int, len(g.apiCalls))
+	for operation, calls := range g.apiCalls {
+		result[operation] = calls
+	}
+	return result
+}
+
+// Counts an API call of the operation. The caller holds mu.
+func (g *GitHubClient) countCall(operation string) {
+	if g.apiCalls == nil {
+		g.apiCalls = make(map[string]int)
+	}
+	g.apiCalls[operation]++
+	g.apiRateUsed++
+}
+
+// Returns the order of the pull requests, OrderNewestFirst unless set otherwise
+func (g *GitHubClient) getOrder() string {
+	if g.order == OrderOldestFirst {
+		return OrderOldestFirst
+	}
+	return OrderNewestFirst
+}
+
+// Returns the most pages a paginated call fetches, DefaultMaxPages unless set otherwise
+func (g *GitHubClient) getMaxPages() int {
+	if g.maxPages <= 0 {
+		return DefaultMaxPages
+	}
+	return g.maxPages
+}
+
+// Returns the page to fetch after the current one, or 0 when the pagination ends. A misbehaving API pointing back to
+// a page already fetched, or beyond the most pages, would loop forever, so that ends the pagination with a warning.
+func (g *GitHubClient) nextPage(resp *github.Response, current int) int {
+	if resp == nil || resp.NextPage == 0 {
+		return 0
+	}
+
+	// The first page is requested as page 0
+	current = max(current, 1)
+	path := ""
+	if resp.Request != nil {
+		path = resp.Request.URL.Path
+	}
+
+	if resp.NextPage <= current {
+		log.Printf("Warning: %s pointed back to page %d after page %d, stopping the pagination\n", path, resp.NextPage, current)
+		return 0
+	}
+	if resp.NextPage > g.getMaxPages() {
+		log.Printf("Warning: %s has more than %d pages, stopping the pagination\n", path, g.getMaxPages())
+		return 0
+	}
+	return resp.NextPage
+}
+
 type Logger interface {
 	Info(msg string)
 	Error(err error)
 }
 
-func NewGitHubClient(token string) (*GitHubClient, error) {
+// Defaults for the HTTP client used to talk to GitHub
+const (
+	DefaultTimeout             = 60 * time.Second
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultMaxPages            = 1000
+)
+
+// Orders of the pull requests by creation date, as the sort directions of the GitHub API
+const (
+	OrderNewestFirst = "desc"
+	OrderOldestFirst = "asc"
+)
+
+// ClientOptions tunes the HTTP client used to talk to GitHub. Zero values fall back to the defaults.
+type ClientOptions struct {
+	Timeout             time.Duration // Limit for a single request, including reading the response body
+	MaxIdleConnsPerHost int           // Idle connections kept open for reuse; all requests go to the same host
+	UseSearch           bool          // Fetch only the pull requests in the date range through the search API, falling back to listing them
+	MinRemaining        int           // Rate limit kept as headroom for other tooling; reaching it stops or waits like an exhausted limit
+	WaitForReset        bool          // Wait until the rate limit resets instead of stopping when it is exhausted or below MinRemaining
+	RequestsPerSecond   float64       // Upper bound of the request rate across all calls, e.g. 5.0. Zero spreads the remaining rate limit until its reset.
+	Order               string        // Order GetPullRequests returns the pull requests in, one of the Order constants. Empty is OrderNewestFirst.
+	MaxPages            int           // Pages fetched by a single paginated call at most, guarding against pagination that never ends
+	SkipValidation      bool          // Don't spend a request on checking the token at construction; a bad token fails the first call instead
+
+	// Proxy to send the requests through, e.g. http://proxy.corp:3128. Empty uses HTTP_PROXY and HTTPS_PROXY.
+	ProxyURL string
+	// PEM file of the certificates to trust in addition to the system ones, e.g. of a proxy inspecting TLS. Empty
+	// trusts the system certificates only.
+	CABundle string
+}
+
+func NewGitHubClient(token string, options ClientOptions) (*GitHubClient, error) {
+	httpClient, err := newHTTPClient(token, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create github client: %w", err)
+	}
+	client := github.NewClient(httpClient)
+
+	result := &GitHubClient{
+		client:       client,
+		limiter:      newLimiter(options.RequestsPerSecond),
+		maxRPS:       options.RequestsPerSecond,
+		useSearch:    options.UseSearch,
+		minRemaining: options.MinRemaining,
+		waitForReset: options.WaitForReset,
+		order:        options.Order,
+		maxPages:     options.MaxPages,
+	}
+	if options.SkipValidation {
+		return result, nil
+	}
+
+	// Check if authentication was successful, keeping the login for GetAuthenticatedUser
+	user, resp, err := client.Users.Get(context.Background(), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create github client: %w", wrapError(err, "failed to authenticate"))
+	}
+	result.countCall(OperationUser)
+	if resp != nil {
+		result.seedLimiter(resp.Rate)
+	}
+	result.login = user.GetLogin()
+
+	return result, nil
+}
+
+// Creates the HTTP client authenticating with the token, with the timeout, connection reuse, proxy and trusted
+// certificates applied.
+func newHTTPClient(token string, options ClientOptions) (*http.Client, error) {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if options.MaxIdleConnsPerHost == 0 {
+		options.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", options.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if options.CABundle != "" {
+		rootCAs, err := loadCABundle(options.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+	}
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(context.Background(), ts)
 
-	client := github.NewClient(tc)
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: &oauth2.Transport{Source: ts, Base: transport},
+	}, nil
+}
+
+// Returns the system certificates together with the ones of the PEM file
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA bundle: %w", err)
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in the CA bundle %s", path)
+	}
+
+	return rootCAs, nil
+}
+
+// Creates the token bucket shared by all calls. A burst of one spreads the requests evenly instead of letting them pile up.
+// Without a request rate it is unlimited until the first response with the rate limit seeds it.
+func newLimiter(requestsPerSecond float64) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 1)
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+}
+
+// Sets the request rate of the limiter to spread the rate limit remaining above MinRemaining over the time until its
+// reset, never exceeding maxRPS. Limits that are exhausted or unknown leave the rate as it is, for verifyRateLimit.
+func (g *GitHubClient) seedLimiter(limit github.Rate) {
+	if g.limiter == nil || limit.Reset.IsZero() {
+		return
+	}
+
+	remaining := limit.Remaining - g.minRemaining
+	untilReset := time.Until(limit.Reset.Time)
+	if remaining <= 0 || untilReset <= 0 {
+		return
+	}
+
+	requestsPerSecond := float64(remaining) / untilReset.Seconds()
+	if g.maxRPS > 0 {
+		requestsPerSecond = min(requestsPerSecond, g.maxRPS)
+	}
+	g.limiter.SetLimit(rate.Limit(requestsPerSecond))
+}
+
+// Returns the login of the user the token belongs to. It's only requested once, or not at all when validating the
+// token at construction already returned it.
+func (g *GitHubClient) GetAuthenticatedUser() (string, error) {
+	g.mu.Lock()
+	login := g.login
+	g.mu.Unlock()
+	if login != "" {
+		return login, nil
+	}
+
+	user, resp, err := withAbuseRetry(g, func() (*github.User, *github.Response, error) {
+		return g.client.Users.Get(context.Background(), "")
+	})
+	if err != nil {
+		return "", wrapError(err, "failed to get the authenticated user")
+	}
+	if err := g.verifyRateLimit(resp, OperationUser); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	g.login = user.GetLogin()
+	g.mu.Unlock()
+
+	return user.GetLogin(), nil
+}
 
-	// Check if authentication was successful
-	_, _, err := client.Users.Get(context.Background(), "")
+// Returns the current core rate limit of the token. Querying the rate limit does not count against it.
+func (g *GitHubClient) GetRateLimitStatus() (*RateLimitStatus, error) {
+	limits, _, err := g.client.RateLimits(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create github client: %v", err)
+		return nil, wrapError(err, "failed to get the rate limit")
+	}
+
+	core := limits.GetCore()
+	g.mu.Lock()
+	g.apiRateRemaining = core.Remaining
+	g.mu.Unlock()
+	g.seedLimiter(*core)
+
+	return &RateLimitStatus{Limit: core.Limit, Remaining: core.Remaining, Reset: core.Reset.Time}, nil
+}
+
+// Returns the names of the repositories of the organization in ascending order, without the archived ones and forks
+// unless the filter includes them.
+func (g *GitHubClient) GetOrgRepos(org string, filter RepoFilter) ([]string, error) {
+	ctx := context.Background()
+	names := []string{}
+
+	opts := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: github.ListOptions{PerPage: 100}}
+
+	// Paginate through all repositories
+	for {
+		repos, resp, err := withAbuseRetry(g, func() ([]*github.Repository, *github.Response, error) {
+			return g.client.Repositories.ListByOrg(ctx, org, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to list the repositories of %s", org))
+		}
+		if err := g.verifyRateLimit(resp, OperationRepos); err != nil {
+			return nil, err
+		}
+
+		for _, repo := range repos {
+			if (repo.GetArchived() && !filter.IncludeArchived) || (repo.GetFork() && !filter.IncludeForks) {
+				continue
+			}
+			names = append(names, repo.GetName())
+		}
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	// Keep the per-repository output stable across runs
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Returns the logins of the members of the organization team
+func (g *GitHubClient) GetTeamMembers(org string, teamSlug string) ([]string, error) {
+	ctx := context.Background()
+	logins := []string{}
+
+	opts := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	// Paginate through all members
+	for {
+		members, resp, err := withAbuseRetry(g, func() ([]*github.User, *github.Response, error) {
+			return g.client.Teams.ListTeamMembersBySlug(ctx, org, teamSlug, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to list the members of team %s/%s", org, teamSlug))
+		}
+		if err := g.verifyRateLimit(resp, OperationTeamMembers); err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			logins = append(logins, member.GetLogin())
+		}
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
 	}
 
-	return &GitHubClient{client: client, apiRateUsed: 1}, nil
+	return logins, nil
 }
 
 func (g *GitHubClient) GetPullRequests(owner string, repo string, dateFrom, DateTo time.Time) ([]*PullRequest, error) {
+	// The search API is unavailable on some servers and may return incomplete results, then list them instead
+	if g.useSearch {
+		if prs, err := g.searchPullRequests(owner, repo, dateFrom, DateTo); err == nil {
+			return prs, nil
+		}
+	}
+
 	ctx := context.Background()
 	allPRs := []*PullRequest{}
 
 	opts := &github.PullRequestListOptions{
 		State:       "all",                           // Fetch all pull requests (open, closed, merged)
 		Sort:        "created",                       // Sort by creation date
-		Direction:   "desc",                          // Descending order
+		Direction:   g.getOrder(),                    // Newest or oldest first
 		ListOptions: github.ListOptions{PerPage: 50}, // Number of pull requests per page
 	}
 
 	// Paginate through all pull requests
 	for {
-		prs, resp, err := g.client.PullRequests.List(ctx, owner, repo, opts)
-		g.verifyRateLimit(resp)
+		prs, resp, err := withAbuseRetry(g, func() ([]*github.PullRequest, *github.Response, error) {
+			return g.client.PullRequests.List(ctx, owner, repo, opts)
+		})
+		if rateErr := g.verifyRateLimit(resp, OperationPullRequests); err == nil {
+			err = rateErr
+		}
 		if err != nil {
-			return nil, err
+			return nil, wrapRepoError(err, owner, repo, "failed to list the pull requests")
 		}
 
 		// Filter pull requests within the time range
-		prsFiltered, found, foundBeforeDateFrom := filterPullRequests(prs, dateFrom, DateTo)
+		prsFiltered, found, foundPastRange := filterPullRequests(prs, dateFrom, DateTo, g.getOrder())
 
 		if found {
 			allPRs = append(allPRs, newPullRequestSlice(prsFiltered)...)
 		}
 
-		// Exit if reached the earliest record, or if there are no more pages
-		if resp.NextPage == 0 || foundBeforeDateFrom {
+		// Exit if reached the far end of the range, or if there are no more pages
+		if foundPastRange {
 			break
 		}
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	return allPRs, nil
+}
+
+// The search API returns at most this many results for a query
+const maxSearchResults = 1000
+
+// Fetches the pull requests created in the date range with a single search query, in the order of the client.
+func (g *GitHubClient) searchPullRequests(owner string, repo string, dateFrom, dateTo time.Time) ([]*PullRequest, error) {
+	ctx := context.Background()
+	allPRs := []*PullRequest{}
+
+	query := fmt.Sprintf("repo:%s/%s type:pr created:%s..%s", owner, repo, dateFrom.UTC().Format(time.RFC3339), dateTo.UTC().Format(time.RFC3339))
+	opts := &github.SearchOptions{
+		Sort:        "created",
+		Order:       g.getOrder(),
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	// Paginate through all search results
+	for {
+		// The search API has its own rate limit, so only the usage is tracked
+		result, resp, err := withAbuseRetry(g, func() (*github.IssuesSearchResult, *github.Response, error) {
+			return g.client.Search.Issues(ctx, query, opts)
+		})
+		g.mu.Lock()
+		g.countCall(OperationPullRequests)
+		g.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
 
-		opts.Page = resp.NextPage
+		if result.GetIncompleteResults() || result.GetTotal() > maxSearchResults {
+			return nil, errors.New("search results for " + query + " are incomplete")
+		}
+
+		allPRs = append(allPRs, mapSlice(result.Issues, newPullRequestFromIssue)...)
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
 	}
 
 	return allPRs, nil
 }
 
-func filterPullRequests(prs []*github.PullRequest, dateFrom time.Time, dateTo time.Time) (result []*github.PullRequest, found bool, foundBeforeDateFrom bool) {
+// Returns the pull requests created in the date range out of a page sorted by creation date in the order, and whether
+// the page reached past the far end of the range: before dateFrom newest first, or after dateTo oldest first. The
+// later pages can't include any more pull requests of the range then.
+func filterPullRequests(prs []*github.PullRequest, dateFrom time.Time, dateTo time.Time, order string) (result []*github.PullRequest, found bool, foundPastRange bool) {
 	if len(prs) == 0 {
 		return nil, false, false
 	}
@@ -91,73 +514,421 @@ func filterPullRequests(prs []*github.PullRequest, dateFrom time.Time, dateTo ti
 	// Initialize indices
 	startIndex := -1
 	endIndex := -1
-	foundBeforeDateFrom = false
-	found = false
 
 	for i, pr := range prs {
 		createdAt := pr.GetCreatedAt()
+		before := createdAt.Before(dateFrom)
+		after := createdAt.After(dateTo)
 
-		if createdAt.Before(dateFrom) {
-			foundBeforeDateFrom = true
-			break // No need to continue since the list is sorted in descending order
-		} else {
-			endIndex = i
+		if (order == OrderOldestFirst && after) || (order != OrderOldestFirst && before) {
+			foundPastRange = true
+			break // No need to continue since the list is sorted
 		}
 
-		if createdAt.After(dateTo) {
-			continue // Skip records outside the upper boundary
-		} else {
-			found = true
-			if startIndex == -1 {
-				startIndex = i
-			}
+		if before || after {
+			continue // Skip records outside the near boundary
+		}
+
+		found = true
+		if startIndex == -1 {
+			startIndex = i
 		}
+		endIndex = i
 	}
 
 	// Handle case where no pull requests match the conditions
 	if !found {
-		return nil, false, foundBeforeDateFrom // Return an empty slice to indicate no matching PRs
+		return nil, false, foundPastRange // Return an empty slice to indicate no matching PRs
 	}
 
 	// Return the slice of pull requests between startIndex and endIndex
-	return prs[startIndex : endIndex+1], found, foundBeforeDateFrom
+	return prs[startIndex : endIndex+1], found, foundPastRange
+}
+
+// GetPullRequestsUpdated returns the pull requests updated since dateFrom and created until dateTo, most recently
+// updated first. Submitting a review updates the pull request, so these hold every review submitted in the range.
+func (g *GitHubClient) GetPullRequestsUpdated(owner string, repo string, dateFrom, dateTo time.Time) ([]*PullRequest, error) {
+	ctx := context.Background()
+	allPRs := []*PullRequest{}
+
+	opts := &github.PullRequestListOptions{
+		State:       "all",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 50},
+	}
+
+	for {
+		prs, resp, err := withAbuseRetry(g, func() ([]*github.PullRequest, *github.Response, error) {
+			return g.client.PullRequests.List(ctx, owner, repo, opts)
+		})
+		if rateErr := g.verifyRateLimit(resp, OperationPullRequests); err == nil {
+			err = rateErr
+		}
+		if err != nil {
+			return nil, wrapRepoError(err, owner, repo, "failed to list the pull requests")
+		}
+
+		for _, pr := range prs {
+			if pr.GetUpdatedAt().Before(dateFrom) {
+				return allPRs, nil // The rest was updated even earlier
+			}
+			if !pr.GetCreatedAt().After(dateTo) {
+				allPRs = append(allPRs, newPullRequest(pr))
+			}
+		}
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			return allPRs, nil
+		}
+	}
 }
 
+// Returns all review comments of the pull request. Comments are listed separately from reviews, so a
+// review on one page may have its comments on any page of this list; they're joined by PullRequestReviewID.
 func (g *GitHubClient) GetComments(owner string, repo string, prNumber int) ([]*PullRequestComment, error) {
 	ctx := context.Background()
+	allComments := []*PullRequestComment{}
+
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	// Paginate through all comments
+	for {
+		comments, resp, err := withAbuseRetry(g, func() ([]*github.PullRequestComment, *github.Response, error) {
+			return g.client.PullRequests.ListComments(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to fetch the comments of %s/%s#%d", owner, repo, prNumber))
+		}
+		if err := g.verifyRateLimit(resp, OperationComments); err != nil {
+			return nil, err
+		}
+
+		allComments = append(allComments, newPullRequestCommentSlice(comments)...)
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	return allComments, nil
+}
+
+// Returns the comments of the general discussion of the pull request, which GitHub keeps as issue comments
+func (g *GitHubClient) GetIssueComments(owner string, repo string, prNumber int) ([]*IssueComment, error) {
+	ctx := context.Background()
+	allComments := []*IssueComment{}
+
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	// Paginate through all comments
+	for {
+		comments, resp, err := withAbuseRetry(g, func() ([]*github.IssueComment, *github.Response, error) {
+			return g.client.Issues.ListComments(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to fetch the issue comments of %s/%s#%d", owner, repo, prNumber))
+		}
+		if err := g.verifyRateLimit(resp, OperationIssueComments); err != nil {
+			return nil, err
+		}
+
+		allComments = append(allComments, mapSlice(comments, newIssueComment)...)
 
-	comments, resp, err := g.client.PullRequests.ListComments(ctx, owner, repo, prNumber, nil)
-	g.verifyRateLimit(resp)
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
 
-	return newPullRequestCommentSlice(comments), err
+	return allComments, nil
 }
 
 func (g *GitHubClient) GetReviews(owner string, repo string, prNumber int) ([]*PullRequestReview, error) {
 	ctx := context.Background()
+	allReviews := []*PullRequestReview{}
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	// Paginate through all reviews
+	for {
+		reviews, resp, err := withAbuseRetry(g, func() ([]*github.PullRequestReview, *github.Response, error) {
+			return g.client.PullRequests.ListReviews(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to fetch the reviews of %s/%s#%d", owner, repo, prNumber))
+		}
+		if err := g.verifyRateLimit(resp, OperationReviews); err != nil {
+			return nil, err
+		}
 
-	reviews, resp, err := g.client.PullRequests.ListReviews(ctx, owner, repo, prNumber, nil)
-	g.verifyRateLimit(resp)
+		allReviews = append(allReviews, newPullRequestReviewSlice(reviews)...)
 
-	return newPullRequestReviewSlice(reviews), err
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	g.mu.Lock()
+	g.prsFetched++
+	g.mu.Unlock()
+
+	return allReviews, nil
+}
+
+// Reviews with their last body edit, which only the GraphQL API reports
+const reviewEditsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviews(first: 100, after: $cursor) {
+        nodes { databaseId lastEditedAt }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+type reviewEditsResponse struct {
+	Data struct {
+		Repository struct {
+			PullRequest struct {
+				Reviews struct {
+					Nodes []struct {
+						DatabaseID   int64      `json:"databaseId"`
+						LastEditedAt *time.Time `json:"lastEditedAt"`
+					} `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool    `json:"hasNextPage"`
+						EndCursor   *string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"reviews"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
 }
 
-func (g *GitHubClient) GetCommits(owner string, repo string, prNumber int, firstCommentTime time.Time, includeFiles bool) ([]*RepositoryCommit, []error) {
+// GetReviewEdits returns the last edit time of the reviews by review ID. Reviews that were never edited are left out.
+func (g *GitHubClient) GetReviewEdits(owner string, repo string, prNumber int) (map[int64]time.Time, error) {
+	ctx := context.Background()
+	description := fmt.Sprintf("failed to fetch the review edits of %s/%s#%d", owner, repo, prNumber)
+	edits := make(map[int64]time.Time)
+
+	var cursor *string
+	pages := 0
+	for {
+		body := map[string]any{
+			"query":     reviewEditsQuery,
+			"variables": map[string]any{"owner": owner, "repo": repo, "number": prNumber, "cursor": cursor},
+		}
+
+		// The request is built on every attempt, as sending it consumes the body
+		result, resp, err := withAbuseRetry(g, func() (*reviewEditsResponse, *github.Response, error) {
+			req, err := g.client.NewRequest(http.MethodPost, "graphql", body)
+			if err != nil {
+				return nil, nil, err
+			}
+			var result reviewEditsResponse
+			resp, err := g.client.Do(ctx, req, &result)
+			return &result, resp, err
+		})
+		if rateErr := g.verifyRateLimit(resp, OperationReviewEdits); err == nil {
+			err = rateErr
+		}
+		if err != nil {
+			return nil, wrapError(err, description)
+		}
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("%s: %s", description, result.Errors[0].Message)
+		}
+
+		reviews := result.Data.Repository.PullRequest.Reviews
+		for _, node := range reviews.Nodes {
+			if node.LastEditedAt != nil {
+				edits[node.DatabaseID] = *node.LastEditedAt
+			}
+		}
+
+		if !reviews.PageInfo.HasNextPage {
+			return edits, nil
+		}
+
+		// Guard against the API returning the same page again, or never ending the pagination
+		pages++
+		if reviews.PageInfo.EndCursor == nil || (cursor != nil && *cursor == *reviews.PageInfo.EndCursor) {
+			log.Printf("Warning: the review edits of %s/%s#%d returned the same page again, stopping the pagination\n", owner, repo, prNumber)
+			return edits, nil
+		}
+		if pages >= g.getMaxPages() {
+			log.Printf("Warning: the review edits of %s/%s#%d have more than %d pages, stopping the pagination\n", owner, repo, prNumber, g.getMaxPages())
+			return edits, nil
+		}
+		cursor = reviews.PageInfo.EndCursor
+	}
+}
+
+// Review threads with the review that started them, which only the GraphQL API reports
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $cursor: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $cursor) {
+        nodes { isResolved comments(first: 1) { nodes { pullRequestReview { databaseId } } } }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+type reviewThreadsResponse struct {
+	Data struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewThreads struct {
+					Nodes []struct {
+						IsResolved bool `json:"isResolved"`
+						Comments   struct {
+							Nodes []struct {
+								PullRequestReview *struct {
+									DatabaseID int64 `json:"databaseId"`
+								} `json:"pullRequestReview"`
+							} `json:"nodes"`
+						} `json:"comments"`
+					} `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool    `json:"hasNextPage"`
+						EndCursor   *string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"reviewThreads"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Returns the review threads of the pull request with whether they were resolved. Threads whose first comment isn't
+// part of a review are left out.
+func (g *GitHubClient) GetReviewThreads(owner string, repo string, prNumber int) ([]*ReviewThread, error) {
+	ctx := context.Background()
+	description := fmt.Sprintf("failed to fetch the review threads of %s/%s#%d", owner, repo, prNumber)
+	threads := []*ReviewThread{}
+
+	var cursor *string
+	pages := 0
+	for {
+		body := map[string]any{
+			"query":     reviewThreadsQuery,
+			"variables": map[string]any{"owner": owner, "repo": repo, "number": prNumber, "cursor": cursor},
+		}
+
+		// The request is built on every attempt, as sending it consumes the body
+		result, resp, err := withAbuseRetry(g, func() (*reviewThreadsResponse, *github.Response, error) {
+			req, err := g.client.NewRequest(http.MethodPost, "graphql", body)
+			if err != nil {
+				return nil, nil, err
+			}
+			var result reviewThreadsResponse
+			resp, err := g.client.Do(ctx, req, &result)
+			return &result, resp, err
+		})
+		if rateErr := g.verifyRateLimit(resp, OperationReviewThreads); err == nil {
+			err = rateErr
+		}
+		if err != nil {
+			return nil, wrapError(err, description)
+		}
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("%s: %s", description, result.Errors[0].Message)
+		}
+
+		reviewThreads := result.Data.Repository.PullRequest.ReviewThreads
+		for _, node := range reviewThreads.Nodes {
+			if len(node.Comments.Nodes) > 0 && node.Comments.Nodes[0].PullRequestReview != nil {
+				threads = append(threads, &ReviewThread{ReviewID: node.Comments.Nodes[0].PullRequestReview.DatabaseID, Resolved: node.IsResolved})
+			}
+		}
+
+		if !reviewThreads.PageInfo.HasNextPage {
+			return threads, nil
+		}
+
+		// Guard against the API returning the same page again, or never ending the pagination
+		pages++
+		if reviewThreads.PageInfo.EndCursor == nil || (cursor != nil && *cursor == *reviewThreads.PageInfo.EndCursor) {
+			log.Printf("Warning: the review threads of %s/%s#%d returned the same page again, stopping the pagination\n", owner, repo, prNumber)
+			return threads, nil
+		}
+		if pages >= g.getMaxPages() {
+			log.Printf("Warning: the review threads of %s/%s#%d have more than %d pages, stopping the pagination\n", owner, repo, prNumber, g.getMaxPages())
+			return threads, nil
+		}
+		cursor = reviewThreads.PageInfo.EndCursor
+	}
+}
+
+// Returns the commits of the pull request. The changed files are only fetched for the commits after firstCommentTime
+// and only those matching paths are kept. No files are fetched when paths is empty.
+func (g *GitHubClient) GetCommits(owner string, repo string, prNumber int, firstCommentTime time.Time, paths []string) ([]*RepositoryCommit, []error) {
 	ctx := context.Background()
 	errs := make([]error, 0)
 
-	commits, resp, err := g.client.PullRequests.ListCommits(ctx, owner, repo, prNumber, nil)
-	g.verifyRateLimit(resp)
-	processError(&err, &errs)
+	wanted := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		wanted[path] = true
+	}
+
+	var commits []*github.RepositoryCommit
+	opts := &github.ListOptions{PerPage: 100}
+
+	// Paginate through all commits, keeping the pages listed before an error
+	for {
+		page, resp, err := withAbuseRetry(g, func() ([]*github.RepositoryCommit, *github.Response, error) {
+			return g.client.PullRequests.ListCommits(ctx, owner, repo, prNumber, opts)
+		})
+		if rateErr := g.verifyRateLimit(resp, OperationCommits); err == nil {
+			err = rateErr
+		}
+		err = wrapError(err, fmt.Sprintf("failed to fetch the commits of %s/%s#%d", owner, repo, prNumber))
+		processError(&err, &errs)
+
+		commits = append(commits, page...)
+
+		if len(errs) > 0 {
+			break
+		}
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
 
 	for _, commit := range commits {
 		if commit.Commit.Committer.Date.After(firstCommentTime) {
-			if includeFiles {
+			if len(wanted) > 0 {
 				// Fetch the files changed in this commit
-				detailedCommit, resp, err := g.client.Repositories.GetCommit(ctx, owner, repo, commit.GetSHA(), nil)
-				g.verifyRateLimit(resp)
+				detailedCommit, resp, err := withAbuseRetry(g, func() (*github.RepositoryCommit, *github.Response, error) {
+					return g.client.Repositories.GetCommit(ctx, owner, repo, commit.GetSHA(), nil)
+				})
+				if rateErr := g.verifyRateLimit(resp, OperationCommitDetails); err == nil {
+					err = rateErr
+				}
+				err = wrapError(err, fmt.Sprintf("failed to fetch commit %s of %s/%s", commit.GetSHA(), owner, repo))
 				processError(&err, &errs)
 
-				commit.Files = detailedCommit.Files
+				if detailedCommit != nil {
+					commit.Files = filterCommitFiles(detailedCommit.Files, wanted)
+				}
+
+				// Stop fetching more commits once the rate limit floor is reached
+				if errors.Is(err, ErrRateLimitReached) {
+					break
+				}
 			}
 		}
 	}
@@ -165,6 +936,192 @@ func (g *GitHubClient) GetCommits(owner string, repo string, prNumber int, first
 	return newRepositoryCommitSlice(commits), errs
 }
 
+// Returns the files whose current or previous name is among the wanted paths
+func filterCommitFiles(files []*github.CommitFile, wanted map[string]bool) []*github.CommitFile {
+	result := make([]*github.CommitFile, 0, len(files))
+	for _, file := range files {
+		if wanted[file.GetFilename()] || wanted[file.GetPreviousFilename()] {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// Returns the size of the pull request. The stats are only included when fetching a single pull request, not in the list.
+func (g *GitHubClient) GetLineStats(owner string, repo string, prNumber int) (*LineStats, error) {
+	ctx := context.Background()
+
+	pr, resp, err := withAbuseRetry(g, func() (*github.PullRequest, *github.Response, error) {
+		return g.client.PullRequests.Get(ctx, owner, repo, prNumber)
+	})
+	if err != nil {
+		return nil, wrapError(err, fmt.Sprintf("failed to fetch %s/%s#%d", owner, repo, prNumber))
+	}
+	if err := g.verifyRateLimit(resp, OperationLineStats); err != nil {
+		return nil, err
+	}
+
+	return &LineStats{Additions: pr.GetAdditions(), Deletions: pr.GetDeletions(), ChangedFiles: pr.GetChangedFiles()}, nil
+}
+
+// Returns the paths of the files changed by the pull request
+func (g *GitHubClient) GetChangedFiles(owner string, repo string, prNumber int) ([]string, error) {
+	files, err := g.listChangedFiles(owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	allFiles := make([]string, len(files))
+	for i, file := range files {
+		allFiles[i] = file.GetFilename()
+	}
+
+	return allFiles, nil
+}
+
+// Returns the files changed by the pull request with their patches against the base. GitHub leaves the patch out of
+// binary and very large files.
+func (g *GitHubClient) GetChangedFilePatches(owner string, repo string, prNumber int) ([]*RepositoryCommitFile, error) {
+	files, err := g.listChangedFiles(owner, repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*RepositoryCommitFile, len(files))
+	for i, file := range files {
+		result[i] = &RepositoryCommitFile{Filename: file.Filename, PreviousFilename: file.PreviousFilename, Patch: file.Patch}
+	}
+
+	return result, nil
+}
+
+// Lists all files changed by the pull request
+func (g *GitHubClient) listChangedFiles(owner string, repo string, prNumber int) ([]*github.CommitFile, error) {
+	ctx := context.Background()
+	allFiles := []*github.CommitFile{}
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	// Paginate through all changed files
+	for {
+		files, resp, err := withAbuseRetry(g, func() ([]*github.CommitFile, *github.Response, error) {
+			return g.client.PullRequests.ListFiles(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to fetch the changed files of %s/%s#%d", owner, repo, prNumber))
+		}
+		if err := g.verifyRateLimit(resp, OperationChangedFiles); err != nil {
+			return nil, err
+		}
+
+		allFiles = append(allFiles, files...)
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	return allFiles, nil
+}
+
+// Returns the reactions to the pull request review comment
+func (g *GitHubClient) GetCommentReactions(owner string, repo string, commentID int64) ([]*Reaction, error) {
+	ctx := context.Background()
+	allReactions := []*Reaction{}
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	// Paginate through all reactions
+	for {
+		reactions, resp, err := withAbuseRetry(g, func() ([]*github.Reaction, *github.Response, error) {
+			return g.client.Reactions.ListPullRequestCommentReactions(ctx, owner, repo, commentID, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to fetch the reactions of comment %d in %s/%s", commentID, owner, repo))
+		}
+		if err := g.verifyRateLimit(resp, OperationReactions); err != nil {
+			return nil, err
+		}
+
+		allReactions = append(allReactions, newReactionSlice(reactions)...)
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	return allReactions, nil
+}
+
+func (g *GitHubClient) GetTimelineEvents(owner string, repo string, prNumber int) ([]*TimelineEvent, error) {
+	ctx := context.Background()
+	allEvents := []*TimelineEvent{}
+
+	opts := &github.ListOptions{PerPage: 100}
+
+	// Paginate through all timeline events
+	for {
+		events, resp, err := withAbuseRetry(g, func() ([]*github.Timeline, *github.Response, error) {
+			return g.client.Issues.ListIssueTimeline(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			return nil, wrapError(err, fmt.Sprintf("failed to fetch the timeline of %s/%s#%d", owner, repo, prNumber))
+		}
+		if err := g.verifyRateLimit(resp, OperationTimeline); err != nil {
+			return nil, err
+		}
+
+		allEvents = append(allEvents, newTimelineEventSlice(events)...)
+
+		opts.Page = g.nextPage(resp, opts.Page)
+		if opts.Page == 0 {
+			break
+		}
+	}
+
+	return allEvents, nil
+}
+
+// Handling of the abuse-detection (secondary) rate limit, reported as 403 with Retry-After regardless of the remaining quota
+const (
+	maxAbuseRetries        = 3
+	defaultAbuseRetryAfter = time.Minute // GitHub asks to wait at least a minute when Retry-After is missing
+)
+
+// Calls the API once the shared limiter allows it, waiting for the Retry-After duration and trying again when the
+// abuse-detection rate limit is hit.
+// Gives up after maxAbuseRetries retries and returns the last error.
+func withAbuseRetry[T any](g *GitHubClient, call func() (T, *github.Response, error)) (T, *github.Response, error) {
+	sleep := g.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	for attempt := 0; ; attempt++ {
+		if g.limiter != nil {
+			if err := g.limiter.Wait(context.Background()); err != nil {
+				var zero T
+				return zero, nil, fmt.Errorf("failed to wait for the request rate limiter: %w", err)
+			}
+		}
+
+		result, resp, err := call()
+
+		var abuseErr *github.AbuseRateLimitError
+		if !errors.As(err, &abuseErr) || attempt == maxAbuseRetries {
+			return result, resp, err
+		}
+
+		retryAfter := defaultAbuseRetryAfter
+		if abuseErr.RetryAfter != nil {
+			retryAfter = *abuseErr.RetryAfter
+		}
+		sleep(retryAfter)
+	}
+}
+
 // Generic function to transform array of one type to another
 func mapSlice[T any, U any](input []T, transform func(T) U) []U {
 	result := make([]U, len(input))
@@ -176,7 +1133,51 @@ func mapSlice[T any, U any](input []T, transform func(T) U) []U {
 
 // Creates PullRequest from github.PullRequest
 func newPullRequest(pr *github.PullRequest) *PullRequest {
-	return &PullRequest{Number: *pr.Number, Title: pr.Title, UserLogin: pr.User.Login, CreatedAt: &pr.CreatedAt.Time}
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	mergedState := MergedStateOpen
+	if pr.GetState() == "closed" {
+		if pr.MergedAt != nil {
+			mergedState = MergedStateMerged
+		} else {
+			mergedState = MergedStateClosedUnmerged
+		}
+	}
+
+	result := PullRequest{Number: *pr.Number, Title: pr.Title, UserLogin: pr.User.Login, UserID: pr.User.ID, CreatedAt: &pr.CreatedAt.Time, Labels: labels, MergedState: mergedState}
+
+	if pr.MergedAt != nil {
+		result.MergedAt = &pr.MergedAt.Time
+	}
+
+	if pr.Head != nil {
+		result.HeadSHA = pr.Head.SHA
+		result.HeadRef = pr.Head.Ref
+	}
+	if pr.Base != nil {
+		result.BaseSHA = pr.Base.SHA
+	}
+
+	return &result
+}
+
+// Creates PullRequest from a github.Issue found by the search API. Pull requests are returned as issues there.
+func newPullRequestFromIssue(issue *github.Issue) *PullRequest {
+	labels := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	// The search results don't tell merged and closed pull requests apart
+	mergedState := ""
+	if issue.GetState() == "open" {
+		mergedState = MergedStateOpen
+	}
+
+	return &PullRequest{Number: *issue.Number, Title: issue.Title, UserLogin: issue.User.Login, UserID: issue.User.ID, CreatedAt: &issue.CreatedAt.Time, Labels: labels, MergedState: mergedState}
 }
 
 // Creates PullRequest slice from github.PullRequest slice
@@ -191,10 +1192,14 @@ func newRepositoryCommitSlice(rcs []*github.RepositoryCommit) []*RepositoryCommi
 
 // Creates RepositoryCommit from github.RepositoryCommit
 func newRepositoryCommit(rc *github.RepositoryCommit) *RepositoryCommit {
-	result := RepositoryCommit{CreatedAt: &rc.Commit.Committer.Date.Time, Files: make([]*RepositoryCommitFile, len(rc.Files))}
+	result := RepositoryCommit{CreatedAt: &rc.Commit.Committer.Date.Time, Message: rc.Commit.GetMessage(), Files: make([]*RepositoryCommitFile, len(rc.Files))}
+
+	if rc.Author != nil {
+		result.AuthorID = rc.Author.ID
+	}
 
 	for i, file := range rc.Files {
-		result.Files[i] = &RepositoryCommitFile{Filename: file.Filename, Patch: file.Patch}
+		result.Files[i] = &RepositoryCommitFile{Filename: file.Filename, PreviousFilename: file.PreviousFilename, Patch: file.Patch}
 	}
 
 	return &result
@@ -202,7 +1207,7 @@ func newRepositoryCommit(rc *github.RepositoryCommit) *RepositoryCommit {
 
 // Creates PullRequestComment from github.PullRequestComment
 func newPullRequestComment(prc *github.PullRequestComment) *PullRequestComment {
-	return &PullRequestComment{PullRequestReviewID: *prc.PullRequestReviewID, UserID: *prc.User.ID, Path: prc.Path, OriginalPosition: *prc.OriginalPosition, CreatedAt: &prc.CreatedAt.Time}
+	return &PullRequestComment{ID: prc.GetID(), ReactionCount: prc.GetReactions().GetTotalCount(), PullRequestReviewID: prc.GetPullRequestReviewID(), UserID: *prc.User.ID, UserLogin: prc.User.Login, Path: prc.Path, Body: prc.GetBody(), AuthorAssociation: prc.GetAuthorAssociation(), OriginalLine: prc.OriginalLine, OriginalStartLine: prc.OriginalStartLine, Side: prc.GetSide(), CreatedAt: &prc.CreatedAt.Time}
 }
 
 // Creates RepositoryCommit slice from github.RepositoryCommit slice
@@ -210,6 +1215,19 @@ func newPullRequestCommentSlice(rcs []*github.PullRequestComment) []*PullRequest
 	return mapSlice(rcs, newPullRequestComment)
 }
 
+// Creates IssueComment from github.IssueComment
+func newIssueComment(ic *github.IssueComment) *IssueComment {
+	result := IssueComment{ID: ic.GetID(), Body: ic.GetBody(), CreatedAt: &ic.CreatedAt.Time}
+
+	// Comments of deleted accounts have no user
+	if ic.User != nil {
+		result.UserID = ic.User.GetID()
+		result.UserLogin = ic.User.Login
+	}
+
+	return &result
+}
+
 // Creates PullRequestReview from github.PullRequestReview
 func newPullRequestReview(prr *github.PullRequestReview) *PullRequestReview {
 
@@ -217,7 +1235,7 @@ func newPullRequestReview(prr *github.PullRequestReview) *PullRequestReview {
 		return nil
 	}
 
-	return &PullRequestReview{ID: *prr.ID, UserID: *prr.User.ID, UserLogin: prr.User.Login, SubmittedAt: &prr.SubmittedAt.Time}
+	return &PullRequestReview{ID: *prr.ID, UserID: *prr.User.ID, UserLogin: prr.User.Login, UserType: prr.User.GetType(), SubmittedAt: &prr.SubmittedAt.Time, State: prr.GetState(), AuthorAssociation: prr.GetAuthorAssociation()}
 }
 
 // Creates RepositoryReview slice from github.RepositoryReview slice
@@ -225,6 +1243,41 @@ func newPullRequestReviewSlice(prr []*github.PullRequestReview) []*PullRequestRe
 	return mapSlice(prr, newPullRequestReview)
 }
 
+// Creates TimelineEvent from github.Timeline
+func newTimelineEvent(event *github.Timeline) *TimelineEvent {
+	result := TimelineEvent{Event: event.GetEvent()}
+
+	if event.CreatedAt != nil {
+		result.CreatedAt = &event.CreatedAt.Time
+	}
+	if event.Reviewer != nil {
+		result.ReviewerLogin = event.Reviewer.Login
+	}
+
+	return &result
+}
+
+// Creates TimelineEvent slice from github.Timeline slice
+func newTimelineEventSlice(events []*github.Timeline) []*TimelineEvent {
+	return mapSlice(events, newTimelineEvent)
+}
+
+// Creates Reaction from github.Reaction
+func newReaction(reaction *github.Reaction) *Reaction {
+	result := Reaction{Content: reaction.GetContent()}
+
+	if reaction.User != nil {
+		result.UserLogin = reaction.User.Login
+	}
+
+	return &result
+}
+
+// Creates Reaction slice from github.Reaction slice
+func newReactionSlice(reactions []*github.Reaction) []*Reaction {
+	return mapSlice(reactions, newReaction)
+}
+
 // Appends the error to the slice if it's not nil.
 func processError(err *error, errs *[]error) {
 	if *err != nil {
@@ -234,14 +1287,104 @@ func processError(err *error, errs *[]error) {
 	}
 }
 
-// Updates API rate usage and checks if the rate limit is exceeded. Returns an error with the reset duration if the limit is reached.
-func (g *GitHubClient) verifyRateLimit(resp *github.Response) error {
-	g.apiRateUsed++
-	g.apiRateRemaining = resp.Rate.Remaining
+// Sentinel errors wrapped by the errors of the client, for inspection with errors.Is
+var (
+	ErrRateLimited  = errors.New("rate limited")         // GitHub rejected the request because of the primary or secondary rate limit
+	ErrRepoNotFound = errors.New("repository not found") // The repository doesn't exist or the token can't access it
+
+	// The organization enforces SAML SSO and the token isn't authorized for it
+	ErrSSORequired = errors.New("SAML SSO authorization required")
+)
+
+// Adds the context to the error and marks rate limit errors with ErrRateLimited, and SSO rejections with ErrSSORequired
+// and the URL to authorize the token at. The underlying error stays inspectable with errors.As. Returns nil for a nil error.
+func wrapError(err error, context string) error {
+	if err == nil {
+		return nil
+	}
+
+	if authorizationURL, ok := getSSOAuthorizationURL(err); ok {
+		if authorizationURL == "" {
+			authorizationURL = "the token settings on GitHub"
+		}
+		return fmt.Errorf("%s: %w, authorize the token for the organization at %s and run again: %w", context, ErrSSORequired, authorizationURL, err)
+	}
+
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return fmt.Errorf("%s: %w: %w", context, ErrRateLimited, err)
+	}
 
-	if resp.Rate.Remaining == 0 {
+	return fmt.Errorf("%s: %w", context, err)
+}
+
+// Returns the authorization URL of the X-GitHub-SSO header of a 403 response, sent when the organization enforces SAML
+// SSO for a token that isn't authorized for it, e.g. "required; url=https://github.com/orgs/org/sso?authorization_request=...".
+// The URL is empty when the header doesn't include it.
+func getSSOAuthorizationURL(err error) (string, bool) {
+	var responseErr *github.ErrorResponse
+	if !errors.As(err, &responseErr) || responseErr.Response == nil || responseErr.Response.StatusCode != http.StatusForbidden {
+		return "", false
+	}
+
+	header := responseErr.Response.Header.Get("X-GitHub-SSO")
+	if !strings.HasPrefix(header, "required") {
+		return "", false
+	}
+	for _, part := range strings.Split(header, ";") {
+		if authorizationURL, found := strings.CutPrefix(strings.TrimSpace(part), "url="); found {
+			return authorizationURL, true
+		}
+	}
+
+	return "", true
+}
+
+// Like wrapError, additionally marking a missing repository with ErrRepoNotFound
+func wrapRepoError(err error, owner string, repo string, context string) error {
+	var responseErr *github.ErrorResponse
+	if errors.As(err, &responseErr) && responseErr.Response != nil && responseErr.Response.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s of %s/%s: %w: %w", context, owner, repo, ErrRepoNotFound, err)
+	}
+
+	return wrapError(err, fmt.Sprintf("%s of %s/%s", context, owner, repo))
+}
+
+// ErrRateLimitReached is returned once the remaining rate limit drops to zero or below the MinRemaining floor
+var ErrRateLimitReached = errors.New("Rate limit reached")
+
+// Counts the API call of the operation, updates API rate usage and checks if the rate limit is exceeded or below the floor. Waits for the reset in wait mode,
+// otherwise returns an error wrapping ErrRateLimitReached with the reset duration.
+func (g *GitHubClient) verifyRateLimit(resp *github.Response, operation string) error {
+	g.mu.Lock()
+	g.countCall(operation)
+	if resp != nil {
+		g.apiRateRemaining = resp.Rate.Remaining
+	}
+	g.mu.Unlock()
+
+	if resp == nil {
+		return nil
+	}
+
+	// Servers without rate limiting don't report it
+	if resp.Rate.Reset.IsZero() {
+		return nil
+	}
+	g.seedLimiter(resp.Rate)
+
+	if resp.Rate.Remaining == 0 || resp.Rate.Remaining < g.minRemaining {
 		duration := time.Until(resp.Rate.Reset.Time)
-		return errors.New("Rate limit reached and will be reset in " + duration.String())
+		if !g.waitForReset {
+			return fmt.Errorf("%w and will be reset in %s", ErrRateLimitReached, duration.String())
+		}
+
+		if g.sleep != nil {
+			g.sleep(duration)
+		} else {
+			time.Sleep(duration)
+		}
 	}
 
 	return nil
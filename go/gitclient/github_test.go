@@ -1,28 +1,144 @@
 package gitclient
 
 import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/go-github/v50/github"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
 func TestNewGitHubClient_Failure(t *testing.T) {
 	token := "invalid-token"
-	_, err := NewGitHubClient(token)
+	_, err := NewGitHubClient(token, ClientOptions{})
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create github client")
 }
 
+func TestNewGitHubClient_SkipValidation(t *testing.T) {
+	client, err := NewGitHubClient("invalid-token", ClientOptions{SkipValidation: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, client.GetApiRateUsed())
+}
+
+func TestNewHTTPClient_Options(t *testing.T) {
+	httpClient, err := newHTTPClient("token", ClientOptions{Timeout: 15 * time.Second, MaxIdleConnsPerHost: 4})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Second, httpClient.Timeout)
+	transport := httpClient.Transport.(*oauth2.Transport)
+	assert.Equal(t, 4, transport.Base.(*http.Transport).MaxIdleConnsPerHost)
+}
+
+func TestNewHTTPClient_Defaults(t *testing.T) {
+	httpClient, err := newHTTPClient("token", ClientOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, httpClient.Timeout)
+	transport := httpClient.Transport.(*oauth2.Transport)
+	assert.Equal(t, DefaultMaxIdleConnsPerHost, transport.Base.(*http.Transport).MaxIdleConnsPerHost)
+}
+
+func TestNewHTTPClient_ProxyAndCABundle(t *testing.T) {
+	// The certificate of a TLS server stands in for the CA of the corporate proxy
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	httpClient, err := newHTTPClient("token", ClientOptions{ProxyURL: "http://proxy.corp:3128", CABundle: caBundle})
+
+	assert.NoError(t, err)
+	transport := httpClient.Transport.(*oauth2.Transport).Base.(*http.Transport)
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	proxyURL, err := transport.Proxy(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.corp:3128", proxyURL.String())
+	_, err = server.Certificate().Verify(x509.VerifyOptions{Roots: transport.TLSClientConfig.RootCAs, DNSName: "example.com"})
+	assert.NoError(t, err)
+}
+
+func TestNewHTTPClient_InvalidCABundle(t *testing.T) {
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caBundle, []byte("not a certificate"), 0o600))
+
+	_, err := newHTTPClient("token", ClientOptions{CABundle: caBundle})
+
+	assert.ErrorContains(t, err, "no certificates found in the CA bundle")
+}
+
 func TestGetApiRateUsed(t *testing.T) {
 	client := &GitHubClient{apiRateUsed: 5}
 	assert.Equal(t, 5, client.GetApiRateUsed())
 }
 
+func TestGetPullRequestsFetched(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"id": 1, "user": {"id": 11, "login": "reviewer1"}, "submitted_at": "2025-01-06T10:00:00Z", "state": "APPROVED"}]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/2/reviews", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	_, err := client.GetReviews("owner", "repo", 1)
+	assert.NoError(t, err)
+	_, err = client.GetReviews("owner", "repo", 2)
+	assert.NoError(t, err)
+
+	// Every page is an API call, while the pull requests are counted once
+	assert.Equal(t, 3, client.GetApiRateUsed())
+	assert.Equal(t, 2, client.GetPullRequestsFetched())
+}
+
+func TestGetApiCallsByOperation(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"id": 1, "user": {"id": 11, "login": "reviewer1"}, "submitted_at": "2025-01-06T10:00:00Z", "state": "APPROVED"}]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+
+	_, err := client.GetPullRequests("owner", "repo", time.Now().Add(-24*time.Hour), time.Now())
+	assert.NoError(t, err)
+	_, err = client.GetReviews("owner", "repo", 1)
+	assert.NoError(t, err)
+	_, err = client.GetComments("owner", "repo", 1)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]int{OperationPullRequests: 1, OperationReviews: 2, OperationComments: 1}, client.GetApiCallsByOperation())
+	assert.Equal(t, 4, client.GetApiRateUsed())
+}
+
 func TestGetApiRateRemaining(t *testing.T) {
 	client := &GitHubClient{apiRateRemaining: 10}
 	assert.Equal(t, 10, client.GetApiRateRemaining())
@@ -37,12 +153,12 @@ func TestVerifyRateLimit(t *testing.T) {
 		},
 	}
 
-	err := client.verifyRateLimit(resp)
+	err := client.verifyRateLimit(resp, OperationReviews)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Rate limit reached")
 
 	resp.Rate.Remaining = 5
-	err = client.verifyRateLimit(resp)
+	err = client.verifyRateLimit(resp, OperationReviews)
 	assert.NoError(t, err)
 	assert.Equal(t, 5, client.GetApiRateRemaining())
 }
@@ -55,13 +171,37 @@ func TestFilterPullRequests(t *testing.T) {
 	dateFrom := time.Now().Add(-3 * time.Hour)
 	dateTo := time.Now().Add(-30 * time.Minute)
 
-	result, found, foundBeforeDateFrom := filterPullRequests(prs, dateFrom, dateTo)
+	result, found, foundPastRange := filterPullRequests(prs, dateFrom, dateTo, OrderNewestFirst)
 
 	assert.True(t, found)
-	assert.False(t, foundBeforeDateFrom)
+	assert.False(t, foundPastRange)
 	assert.Len(t, result, 2)
 }
 
+func TestFilterPullRequests_OldestFirst(t *testing.T) {
+	dateFrom := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	newPR := func(number int, createdAt time.Time) *github.PullRequest {
+		return &github.PullRequest{Number: github.Int(number), CreatedAt: &github.Timestamp{Time: createdAt}}
+	}
+
+	// The first page starts before the range and ends in it
+	page := []*github.PullRequest{newPR(1, dateFrom.AddDate(0, 0, -2)), newPR(2, dateFrom.AddDate(0, 0, 1)), newPR(3, dateFrom.AddDate(0, 0, 2))}
+	result, found, foundPastRange := filterPullRequests(page, dateFrom, dateTo, OrderOldestFirst)
+
+	assert.True(t, found)
+	assert.False(t, foundPastRange, "pull requests before the range don't end the listing oldest first")
+	assert.Equal(t, []*github.PullRequest{page[1], page[2]}, result)
+
+	// The next page runs past the end of the range
+	page = []*github.PullRequest{newPR(4, dateTo.AddDate(0, 0, -1)), newPR(5, dateTo.AddDate(0, 0, 1)), newPR(6, dateTo.AddDate(0, 0, 2))}
+	result, found, foundPastRange = filterPullRequests(page, dateFrom, dateTo, OrderOldestFirst)
+
+	assert.True(t, found)
+	assert.True(t, foundPastRange)
+	assert.Equal(t, []*github.PullRequest{page[0]}, result)
+}
+
 func TestProcessError(t *testing.T) {
 	errs := []error{}
 	err := errors.New("test error")
@@ -80,17 +220,59 @@ func TestNewPullRequest(t *testing.T) {
 	pr := &github.PullRequest{
 		Number: github.Int(1),
 		Title:  github.String("Test PR"),
-		User:   &github.User{Login: github.String("test-user")},
+		User:   &github.User{Login: github.String("test-user"), ID: github.Int64(7)},
 		CreatedAt: &github.Timestamp{
 			Time: now,
 		},
+		Labels: []*github.Label{{Name: github.String("bug")}, {Name: github.String("feature")}},
 	}
 
 	result := newPullRequest(pr)
 	assert.Equal(t, 1, result.Number)
 	assert.Equal(t, "Test PR", *result.Title)
 	assert.Equal(t, "test-user", *result.UserLogin)
+	assert.Equal(t, int64(7), *result.UserID)
 	assert.Equal(t, now, *result.CreatedAt)
+	assert.Equal(t, []string{"bug", "feature"}, result.Labels)
+	assert.Equal(t, MergedStateOpen, result.MergedState)
+	assert.Nil(t, result.HeadSHA)
+	assert.Nil(t, result.BaseSHA)
+}
+
+func TestNewPullRequest_SHAs(t *testing.T) {
+	pr := &github.PullRequest{
+		Number:    github.Int(1),
+		User:      &github.User{Login: github.String("test-user")},
+		CreatedAt: &github.Timestamp{Time: time.Now()},
+		Head:      &github.PullRequestBranch{SHA: github.String("6dcb09b5b57875f334f61aebed695e2e4193db5e"), Ref: github.String("feature")},
+		Base:      &github.PullRequestBranch{SHA: github.String("e5bd3914e2e596debea16f433f57875b5b90bcd6")},
+	}
+
+	result := newPullRequest(pr)
+	assert.Equal(t, "6dcb09b5b57875f334f61aebed695e2e4193db5e", *result.HeadSHA)
+	assert.Equal(t, "feature", *result.HeadRef)
+	assert.Equal(t, "e5bd3914e2e596debea16f433f57875b5b90bcd6", *result.BaseSHA)
+}
+
+func TestNewPullRequest_MergedState(t *testing.T) {
+	newClosed := func(mergedAt *github.Timestamp) *github.PullRequest {
+		return &github.PullRequest{
+			Number:    github.Int(1),
+			State:     github.String("closed"),
+			User:      &github.User{Login: github.String("test-user")},
+			CreatedAt: &github.Timestamp{Time: time.Now()},
+			MergedAt:  mergedAt,
+		}
+	}
+
+	mergedAt := time.Now()
+	merged := newPullRequest(newClosed(&github.Timestamp{Time: mergedAt}))
+	assert.Equal(t, MergedStateMerged, merged.MergedState)
+	assert.Equal(t, mergedAt, *merged.MergedAt)
+
+	closed := newPullRequest(newClosed(nil))
+	assert.Equal(t, MergedStateClosedUnmerged, closed.MergedState)
+	assert.Nil(t, closed.MergedAt)
 }
 
 func TestNewPullRequestSlice(t *testing.T) {
@@ -123,9 +305,11 @@ func TestNewPullRequestCommentSlice(t *testing.T) {
 		User: &github.User{
 			ID: github.Int64(123),
 		},
-		Path:             github.String("//test-path"),
-		OriginalPosition: github.Int(45),
-		CreatedAt:        &github.Timestamp{Time: time.Now()},
+		Path:              github.String("//test-path"),
+		OriginalLine:      github.Int(45),
+		OriginalStartLine: github.Int(43),
+		Side:              github.String(SideRight),
+		CreatedAt:         &github.Timestamp{Time: time.Now()},
 	}
 	result := newPullRequestCommentSlice([]*github.PullRequestComment{comment})
 
@@ -133,18 +317,38 @@ func TestNewPullRequestCommentSlice(t *testing.T) {
 	assert.Equal(t, *comment.PullRequestReviewID, result[0].PullRequestReviewID)
 	assert.Equal(t, *comment.User.ID, result[0].UserID)
 	assert.Equal(t, *comment.Path, *result[0].Path)
-	assert.Equal(t, *comment.OriginalPosition, result[0].OriginalPosition)
+	assert.Equal(t, 45, *result[0].OriginalLine)
+	assert.Equal(t, 43, *result[0].OriginalStartLine)
+	assert.Equal(t, SideRight, result[0].Side)
 	assert.Equal(t, comment.CreatedAt.Time, *result[0].CreatedAt)
 }
 
+func TestNewPullRequestCommentSlice_NilOriginalLine(t *testing.T) {
+	comment := &github.PullRequestComment{
+		PullRequestReviewID: github.Int64(1),
+		User: &github.User{
+			ID: github.Int64(123),
+		},
+		Path:      github.String("//removed-path"),
+		CreatedAt: &github.Timestamp{Time: time.Now()},
+	}
+	result := newPullRequestCommentSlice([]*github.PullRequestComment{comment})
+
+	assert.Len(t, result, 1)
+	assert.Nil(t, result[0].OriginalLine)
+}
+
 func TestNewPullRequestReviewSlice(t *testing.T) {
 	review := &github.PullRequestReview{
 		ID: github.Int64(1),
 		User: &github.User{
 			ID:    github.Int64(123),
 			Login: github.String("login1"),
+			Type:  github.String("User"),
 		},
-		SubmittedAt: &github.Timestamp{Time: time.Now()},
+		SubmittedAt:       &github.Timestamp{Time: time.Now()},
+		State:             github.String("APPROVED"),
+		AuthorAssociation: github.String("MEMBER"),
 	}
 	result := newPullRequestReviewSlice([]*github.PullRequestReview{review})
 
@@ -152,7 +356,10 @@ func TestNewPullRequestReviewSlice(t *testing.T) {
 	assert.Equal(t, *review.ID, result[0].ID)
 	assert.Equal(t, *review.User.ID, result[0].UserID)
 	assert.Equal(t, *review.User.Login, *result[0].UserLogin)
+	assert.Equal(t, "User", result[0].UserType)
 	assert.Equal(t, review.SubmittedAt.Time, *result[0].SubmittedAt)
+	assert.Equal(t, ReviewStateApproved, result[0].State)
+	assert.Equal(t, AuthorAssociationMember, result[0].AuthorAssociation)
 }
 
 func TestMapSlice(t *testing.T) {
@@ -163,3 +370,651 @@ func TestMapSlice(t *testing.T) {
 
 	assert.Equal(t, []string{"Number: 1", "Number: 2", "Number: 3"}, output)
 }
+
+// Creates a GitHubClient talking to a local test server. Handlers are registered on the returned mux.
+func setupTestClient(t *testing.T) (*GitHubClient, *http.ServeMux) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, _ := url.Parse(server.URL + "/")
+	client.BaseURL = baseURL
+
+	return &GitHubClient{client: client}, mux
+}
+
+func TestGetAuthenticatedUser(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		fmt.Fprint(w, `{"login": "octocat", "id": 1}`)
+	})
+
+	login, err := client.GetAuthenticatedUser()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "octocat", login)
+	assert.Equal(t, 4999, client.GetApiRateRemaining())
+
+	// The login is only requested once
+	login, err = client.GetAuthenticatedUser()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "octocat", login)
+	assert.Equal(t, 1, client.GetApiRateUsed())
+}
+
+func TestGetRateLimitStatus(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"resources": {"core": {"limit": 5000, "remaining": 4321, "reset": 1735689600}}}`)
+	})
+
+	status, err := client.GetRateLimitStatus()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5000, status.Limit)
+	assert.Equal(t, 4321, status.Remaining)
+	assert.Equal(t, time.Unix(1735689600, 0).UTC(), status.Reset.UTC())
+}
+
+func TestGetTimelineEvents(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/issues/1/timeline", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"event": "commented", "created_at": "2025-01-06T09:00:00Z"}, {"event": "head_ref_force_pushed", "created_at": "2025-01-06T10:00:00Z"},
+			{"event": "review_requested", "created_at": "2025-01-06T11:00:00Z", "requested_reviewer": {"login": "reviewer1"}}]`)
+	})
+
+	events, err := client.GetTimelineEvents("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, TimelineEventForcePushed, events[1].Event)
+	assert.Equal(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC), events[1].CreatedAt.UTC())
+	assert.Nil(t, events[1].ReviewerLogin)
+	assert.Equal(t, TimelineEventReviewRequested, events[2].Event)
+	assert.Equal(t, "reviewer1", *events[2].ReviewerLogin)
+}
+
+func TestGetLineStats(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 1, "additions": 120, "deletions": 30, "changed_files": 4}`)
+	})
+
+	stats, err := client.GetLineStats("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &LineStats{Additions: 120, Deletions: 30, ChangedFiles: 4}, stats)
+}
+
+func TestGetChangedFiles(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"filename": "api/handler.go", "status": "modified"}, {"filename": "docs/README.md", "status": "added"}]`)
+	})
+
+	files, err := client.GetChangedFiles("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api/handler.go", "docs/README.md"}, files)
+}
+
+func TestGetChangedFilePatches(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"filename": "api/handler.go", "status": "modified", "patch": "@@ -1,2 +1,3 @@"}, {"filename": "logo.png", "status": "added"}]`)
+	})
+
+	files, err := client.GetChangedFilePatches("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*RepositoryCommitFile{
+		{Filename: github.String("api/handler.go"), Patch: github.String("@@ -1,2 +1,3 @@")},
+		{Filename: github.String("logo.png")},
+	}, files)
+}
+
+func TestGetReviews_RepeatingNextPage(t *testing.T) {
+	client, mux := setupTestClient(t)
+	requests := 0
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 10 {
+			t.Fatal("the pagination didn't stop")
+		}
+
+		// Page 2 points back to page 1
+		page := r.URL.Query().Get("page")
+		next := "2"
+		if page == "2" {
+			next = "1"
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=%s>; rel="next"`, r.URL.Path, next))
+		fmt.Fprintf(w, `[{"id": %d, "user": {"id": 11, "login": "reviewer1"}, "submitted_at": "2025-01-06T10:00:00Z", "state": "COMMENTED"}]`, requests)
+	})
+
+	reviews, err := client.GetReviews("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Len(t, reviews, 2)
+}
+
+func TestGetReviews_MaxPages(t *testing.T) {
+	client, mux := setupTestClient(t)
+	client.maxPages = 3
+	requests := 0
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, requests+1))
+		fmt.Fprint(w, `[]`)
+	})
+
+	_, err := client.GetReviews("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requests)
+}
+
+func TestGetPullRequests_Search(t *testing.T) {
+	client, mux := setupTestClient(t)
+	client.useSearch = true
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "repo:owner/repo type:pr created:2025-01-01T00:00:00Z..2025-01-31T23:59:59Z", r.URL.Query().Get("q"))
+		fmt.Fprint(w, `{"total_count": 2, "incomplete_results": false, "items": [
+			{"number": 2, "title": "PR 2", "user": {"login": "contributor2"}, "created_at": "2025-01-20T10:00:00Z", "labels": [{"name": "bug"}]},
+			{"number": 1, "title": "PR 1", "user": {"login": "contributor1"}, "created_at": "2025-01-10T10:00:00Z"}]}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("pull requests should not be listed when the search succeeds")
+	})
+
+	prs, err := client.GetPullRequests("owner", "repo", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Len(t, prs, 2)
+	assert.Equal(t, 2, prs[0].Number)
+	assert.Equal(t, "contributor2", *prs[0].UserLogin)
+	assert.Equal(t, []string{"bug"}, prs[0].Labels)
+	assert.Equal(t, time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC), prs[1].CreatedAt.UTC())
+}
+
+func TestGetPullRequests_SearchUnavailable(t *testing.T) {
+	client, mux := setupTestClient(t)
+	client.useSearch = true
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"number": 3, "title": "PR 3", "user": {"login": "contributor1"}, "created_at": "2025-01-15T10:00:00Z"},
+			{"number": 1, "title": "PR 1", "user": {"login": "contributor1"}, "created_at": "2024-12-15T10:00:00Z"}]`)
+	})
+
+	prs, err := client.GetPullRequests("owner", "repo", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, 3, prs[0].Number)
+}
+
+func TestGetReviewsAndComments_Paginated(t *testing.T) {
+	client, mux := setupTestClient(t)
+	// The review is on the first page of reviews, its comments on the second page of comments
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"id": 2, "user": {"id": 12, "login": "reviewer2"}, "submitted_at": "2025-01-07T10:00:00Z", "state": "APPROVED"}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"id": 1, "user": {"id": 11, "login": "reviewer1"}, "submitted_at": "2025-01-06T10:00:00Z", "state": "COMMENTED"}]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"id": 102, "pull_request_review_id": 1, "user": {"id": 11, "login": "reviewer1"}, "path": "a.go", "created_at": "2025-01-06T10:00:00Z"},
+				{"id": 103, "pull_request_review_id": 1, "user": {"id": 11, "login": "reviewer1"}, "path": "b.go", "created_at": "2025-01-06T10:00:00Z"}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"id": 101, "pull_request_review_id": 2, "user": {"id": 12, "login": "reviewer2"}, "path": "a.go", "created_at": "2025-01-07T10:00:00Z"}]`)
+	})
+
+	reviews, err := client.GetReviews("owner", "repo", 1)
+	assert.NoError(t, err)
+	comments, err := client.GetComments("owner", "repo", 1)
+	assert.NoError(t, err)
+
+	assert.Len(t, reviews, 2)
+	assert.Equal(t, int64(1), reviews[0].ID)
+	assert.Len(t, comments, 3)
+	var reviewComments []int64
+	for _, comment := range comments {
+		if comment.PullRequestReviewID == reviews[0].ID {
+			reviewComments = append(reviewComments, comment.ID)
+		}
+	}
+	assert.Equal(t, []int64{102, 103}, reviewComments)
+}
+
+func TestGetIssueComments(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 101, "user": {"id": 11, "login": "reviewer1"}, "body": "Why not reuse the cache?", "created_at": "2025-01-06T10:00:00Z"}, {"id": 102, "body": "Ghost", "created_at": "2025-01-06T11:00:00Z"}]`)
+	})
+
+	comments, err := client.GetIssueComments("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	createdAt := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	ghostCreatedAt := time.Date(2025, 1, 6, 11, 0, 0, 0, time.UTC)
+	assert.Equal(t, []*IssueComment{
+		{ID: 101, UserID: 11, UserLogin: github.String("reviewer1"), Body: "Why not reuse the cache?", CreatedAt: &createdAt},
+		{ID: 102, Body: "Ghost", CreatedAt: &ghostCreatedAt}, // Posted by a deleted account
+	}, comments)
+	assert.Equal(t, map[string]int{OperationIssueComments: 1}, client.GetApiCallsByOperation())
+}
+
+func TestGetComments_NullReviewID(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 101, "pull_request_review_id": null, "user": {"id": 11, "login": "reviewer1"}, "path": "a.go", "created_at": "2025-01-06T10:00:00Z"}]`)
+	})
+
+	comments, err := client.GetComments("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, int64(0), comments[0].PullRequestReviewID)
+}
+
+func TestGetReviews_AbuseRateLimitRetried(t *testing.T) {
+	client, mux := setupTestClient(t)
+	var waits []time.Duration
+	client.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		time.Sleep(d) // The client refuses requests until Retry-After has passed
+	}
+
+	calls := 0
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have triggered an abuse detection mechanism.", "documentation_url": "https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits"}`)
+			return
+		}
+		fmt.Fprint(w, `[{"id": 1, "user": {"id": 11, "login": "reviewer1"}, "submitted_at": "2025-01-06T10:00:00Z", "state": "APPROVED"}]`)
+	})
+
+	reviews, err := client.GetReviews("owner", "repo", 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []time.Duration{1 * time.Second}, waits)
+}
+
+func TestGetLineStats_AbuseRateLimitGivesUp(t *testing.T) {
+	client, mux := setupTestClient(t)
+	var waits []time.Duration
+	client.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+	}
+
+	// Without Retry-After the client doesn't hold back requests itself
+	mux.HandleFunc("/repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit.", "documentation_url": "https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits"}`)
+	})
+
+	_, err := client.GetLineStats("owner", "repo", 1)
+
+	var abuseErr *github.AbuseRateLimitError
+	assert.ErrorAs(t, err, &abuseErr)
+	assert.Equal(t, []time.Duration{defaultAbuseRetryAfter, defaultAbuseRetryAfter, defaultAbuseRetryAfter}, waits)
+}
+
+func TestGetOrgRepos(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/orgs/org/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "web"}, {"name": "legacy", "archived": true}, {"name": "upstream-fork", "fork": true}, {"name": "api"}]`)
+	})
+
+	repos, err := client.GetOrgRepos("org", RepoFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api", "web"}, repos)
+
+	repos, err = client.GetOrgRepos("org", RepoFilter{IncludeArchived: true, IncludeForks: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api", "legacy", "upstream-fork", "web"}, repos)
+}
+
+func TestVerifyRateLimit_MinRemaining(t *testing.T) {
+	resp := &github.Response{
+		Rate: github.Rate{
+			Remaining: 50,
+			Reset:     github.Timestamp{Time: time.Now().Add(10 * time.Minute)},
+		},
+	}
+
+	// Below the floor the client stops
+	client := &GitHubClient{minRemaining: 100}
+	err := client.verifyRateLimit(resp, OperationReviews)
+	assert.ErrorIs(t, err, ErrRateLimitReached)
+
+	// In wait mode it waits for the reset instead
+	var waits []time.Duration
+	client = &GitHubClient{minRemaining: 100, waitForReset: true, sleep: func(d time.Duration) { waits = append(waits, d) }}
+	err = client.verifyRateLimit(resp, OperationReviews)
+	assert.NoError(t, err)
+	assert.Len(t, waits, 1)
+	assert.InDelta(t, float64(10*time.Minute), float64(waits[0]), float64(time.Minute))
+
+	// Above the floor nothing happens
+	resp.Rate.Remaining = 150
+	client = &GitHubClient{minRemaining: 100}
+	assert.NoError(t, client.verifyRateLimit(resp, OperationReviews))
+}
+
+func TestGetTeamMembers(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/orgs/org/teams/backend/members", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"login": "backend1"}, {"login": "backend2"}]`)
+	})
+
+	members, err := client.GetTeamMembers("org", "backend")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"backend1", "backend2"}, members)
+}
+
+func TestGetCommentReactions(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/comments/101/reactions", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "user": {"login": "contributor1"}, "content": "+1"}, {"id": 2, "content": "eyes"}]`)
+	})
+
+	reactions, err := client.GetCommentReactions("owner", "repo", 101)
+
+	assert.NoError(t, err)
+	assert.Len(t, reactions, 2)
+	assert.Equal(t, "contributor1", *reactions[0].UserLogin)
+	assert.Equal(t, "+1", reactions[0].Content)
+	assert.Nil(t, reactions[1].UserLogin)
+}
+
+func TestGetPullRequests_RepoNotFound(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/missing/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+
+	_, err := client.GetPullRequests("owner", "missing", time.Now().Add(-24*time.Hour), time.Now())
+
+	assert.ErrorIs(t, err, ErrRepoNotFound)
+	assert.False(t, errors.Is(err, ErrRateLimited))
+	var responseErr *github.ErrorResponse
+	assert.ErrorAs(t, err, &responseErr)
+}
+
+func TestGetReviews_RateLimited(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "API rate limit exceeded for user ID 1."}`)
+	})
+
+	_, err := client.GetReviews("owner", "repo", 1)
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+	var rateLimitErr *github.RateLimitError
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.Contains(t, err.Error(), "failed to fetch the reviews of owner/repo#1")
+}
+
+func TestGetReviews_SSORequired(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/reviews", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-SSO", "required; url=https://github.com/orgs/owner/sso?authorization_request=abc123")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization."}`)
+	})
+
+	_, err := client.GetReviews("owner", "repo", 1)
+
+	assert.ErrorIs(t, err, ErrSSORequired)
+	assert.False(t, errors.Is(err, ErrRateLimited))
+	assert.Contains(t, err.Error(), "failed to fetch the reviews of owner/repo#1")
+	assert.Contains(t, err.Error(), "https://github.com/orgs/owner/sso?authorization_request=abc123")
+	var responseErr *github.ErrorResponse
+	assert.ErrorAs(t, err, &responseErr)
+}
+
+func TestGetPullRequests_NotSSO(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource not accessible by personal access token"}`)
+	})
+
+	_, err := client.GetPullRequests("owner", "repo", time.Now().Add(-24*time.Hour), time.Now())
+
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSSORequired))
+}
+
+func TestLimiter_ConcurrentCallsKeepRate(t *testing.T) {
+	client, mux := setupTestClient(t)
+	client.limiter = newLimiter(20)
+	mux.HandleFunc("/repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 1, "additions": 1}`)
+	})
+
+	// With a burst of one, 10 calls at 20 per second take at least 9 intervals of 50ms
+	const calls = 10
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetLineStats("owner", "repo", 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.GreaterOrEqual(t, time.Since(start), 9*50*time.Millisecond-10*time.Millisecond)
+	assert.Equal(t, calls, client.GetApiRateUsed())
+}
+
+func TestNewLimiter_Unlimited(t *testing.T) {
+	assert.Equal(t, rate.Inf, newLimiter(0).Limit())
+}
+
+func TestSeedLimiter_SpreadsRemainingRateLimit(t *testing.T) {
+	client, mux := setupTestClient(t)
+	client.limiter = newLimiter(0)
+	mux.HandleFunc("/repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "3700")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		fmt.Fprint(w, `{"number": 1, "additions": 1}`)
+	})
+
+	// 3600 requests above the floor over an hour is one per second
+	client.minRemaining = 100
+	_, err := client.GetLineStats("owner", "repo", 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.0, float64(client.limiter.Limit()), 0.01)
+
+	// The maximum request rate still caps it
+	client.maxRPS = 0.5
+	_, err = client.GetLineStats("owner", "repo", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, rate.Limit(0.5), client.limiter.Limit())
+}
+
+func TestLimiter_WaitError(t *testing.T) {
+	client, mux := setupTestClient(t)
+	client.limiter = rate.NewLimiter(1, 0) // No burst never allows a request
+	mux.HandleFunc("/repos/owner/repo/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 1, "additions": 1}`)
+	})
+
+	_, err := client.GetLineStats("owner", "repo", 1)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, client.GetApiRateUsed())
+}
+
+func TestGetReviewEdits(t *testing.T) {
+	client, mux := setupTestClient(t)
+	requests := 0
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, float64(7), body.Variables["number"])
+
+		// Two pages, the second one asked for with the cursor of the first
+		requests++
+		if body.Variables["cursor"] == nil {
+			fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {"reviews": {
+				"nodes": [{"databaseId": 1, "lastEditedAt": "2025-01-10T10:00:00Z"}, {"databaseId": 2, "lastEditedAt": null}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}}}}`)
+			return
+		}
+		assert.Equal(t, "c1", body.Variables["cursor"])
+		fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {"reviews": {
+			"nodes": [{"databaseId": 3, "lastEditedAt": "2025-01-11T10:00:00Z"}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "c2"}}}}}}`)
+	})
+
+	edits, err := client.GetReviewEdits("owner", "repo", 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, map[int64]time.Time{
+		1: time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC),
+		3: time.Date(2025, 1, 11, 10, 0, 0, 0, time.UTC),
+	}, edits)
+}
+
+func TestGetReviewEdits_GraphQLError(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": null, "errors": [{"message": "Could not resolve to a Repository"}]}`)
+	})
+
+	_, err := client.GetReviewEdits("owner", "repo", 7)
+
+	assert.ErrorContains(t, err, "Could not resolve to a Repository")
+}
+
+func TestGetReviewThreads(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, float64(7), body.Variables["number"])
+
+		// The last thread was started by a comment outside of a review
+		fmt.Fprint(w, `{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"nodes": [
+				{"isResolved": true, "comments": {"nodes": [{"pullRequestReview": {"databaseId": 1}}]}},
+				{"isResolved": false, "comments": {"nodes": [{"pullRequestReview": {"databaseId": 2}}]}},
+				{"isResolved": false, "comments": {"nodes": [{"pullRequestReview": null}]}}
+			],
+			"pageInfo": {"hasNextPage": false, "endCursor": "c1"}}}}}}`)
+	})
+
+	threads, err := client.GetReviewThreads("owner", "repo", 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ReviewThread{{ReviewID: 1, Resolved: true}, {ReviewID: 2, Resolved: false}}, threads)
+}
+
+func TestGetCommits_KeepsCommentedFiles(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"sha": "abc", "commit": {"committer": {"date": "2025-01-10T10:00:00Z"}}}]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/abc", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"sha": "abc", "commit": {"committer": {"date": "2025-01-10T10:00:00Z"}},
+			"files": [{"filename": "a.go", "patch": "@@ -1 +1 @@"}, {"filename": "vendor/big.go", "patch": "@@ -1 +1 @@"},
+				{"filename": "c.go", "previous_filename": "b.go", "patch": "@@ -1 +1 @@"}]}`)
+	})
+
+	commits, errs := client.GetCommits("owner", "repo", 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), []string{"a.go", "b.go"})
+
+	// The renamed file is kept by its previous name
+	assert.Len(t, errs, 0)
+	assert.Len(t, commits, 1)
+	assert.Len(t, commits[0].Files, 2)
+	assert.Equal(t, "a.go", *commits[0].Files[0].Filename)
+	assert.Equal(t, "b.go", *commits[0].Files[1].PreviousFilename)
+}
+
+func TestGetCommits_Paginated(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/commits", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"sha": "def", "commit": {"committer": {"date": "2025-01-11T10:00:00Z"}}}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"sha": "abc", "commit": {"committer": {"date": "2025-01-10T10:00:00Z"}}}]`)
+	})
+
+	commits, errs := client.GetCommits("owner", "repo", 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+
+	assert.Len(t, errs, 0)
+	assert.Len(t, commits, 2)
+	assert.Equal(t, time.Date(2025, 1, 11, 10, 0, 0, 0, time.UTC), *commits[1].CreatedAt)
+}
+
+func TestGetCommits_NoPathsSkipsDetails(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls/1/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"sha": "abc", "commit": {"committer": {"date": "2025-01-10T10:00:00Z"}}}]`)
+	})
+	mux.HandleFunc("/repos/owner/repo/commits/abc", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the commit details should not be fetched")
+	})
+
+	commits, errs := client.GetCommits("owner", "repo", 1, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+
+	assert.Len(t, errs, 0)
+	assert.Len(t, commits, 1)
+	assert.Empty(t, commits[0].Files)
+}
+
+func TestGetPullRequestsUpdated(t *testing.T) {
+	client, mux := setupTestClient(t)
+	mux.HandleFunc("/repos/owner/repo/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "updated", r.URL.Query().Get("sort"))
+		fmt.Fprint(w, `[
+			{"number": 3, "title": "PR", "user": {"login": "contributor1"}, "created_at": "2025-02-02T00:00:00Z", "updated_at": "2025-02-03T00:00:00Z"},
+			{"number": 2, "title": "PR", "user": {"login": "contributor1"}, "created_at": "2024-12-20T00:00:00Z", "updated_at": "2025-01-10T00:00:00Z"},
+			{"number": 1, "title": "PR", "user": {"login": "contributor1"}, "created_at": "2024-12-01T00:00:00Z", "updated_at": "2024-12-02T00:00:00Z"}]`)
+	})
+
+	prs, err := client.GetPullRequestsUpdated("owner", "repo", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC))
+
+	// PR 3 was created after the range and PR 1 wasn't updated in it
+	assert.NoError(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, 2, prs[0].Number)
+}
@@ -0,0 +1,56 @@
+package gitclient
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v50/github"
+)
+
+// Webhook event types carrying reviews and review comments
+const (
+	WebhookEventReview        = "pull_request_review"
+	WebhookEventReviewComment = "pull_request_review_comment"
+)
+
+// WebhookEvent is a review or review comment delivered by a webhook, together with its pull request
+type WebhookEvent struct {
+	Action      string // e.g. submitted, edited, dismissed for reviews, created, edited, deleted for comments
+	Repo        string // owner/repo
+	PullRequest *PullRequest
+	Review      *PullRequestReview  // Set for WebhookEventReview, nil for pending reviews
+	Comment     *PullRequestComment // Set for WebhookEventReviewComment
+}
+
+// ParseWebhookEvent parses the payload of a webhook delivery of the given type, as sent in the X-GitHub-Event header.
+// Only WebhookEventReview and WebhookEventReviewComment are supported.
+func ParseWebhookEvent(eventType string, payload []byte) (*WebhookEvent, error) {
+	if eventType != WebhookEventReview && eventType != WebhookEventReviewComment {
+		return nil, fmt.Errorf("unsupported webhook event %q", eventType)
+	}
+
+	parsed, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the %s webhook payload: %w", eventType, err)
+	}
+
+	switch event := parsed.(type) {
+	case *github.PullRequestReviewEvent:
+		if event.PullRequest == nil || event.Review == nil {
+			return nil, fmt.Errorf("%s webhook payload without pull request or review", eventType)
+		}
+		review := newPullRequestReview(event.Review)
+		if review != nil {
+			// Webhooks report the states in lower case, unlike the REST API
+			review.State = strings.ToUpper(review.State)
+		}
+		return &WebhookEvent{Action: event.GetAction(), Repo: event.GetRepo().GetFullName(), PullRequest: newPullRequest(event.PullRequest), Review: review}, nil
+	case *github.PullRequestReviewCommentEvent:
+		if event.PullRequest == nil || event.Comment == nil {
+			return nil, fmt.Errorf("%s webhook payload without pull request or comment", eventType)
+		}
+		return &WebhookEvent{Action: event.GetAction(), Repo: event.GetRepo().GetFullName(), PullRequest: newPullRequest(event.PullRequest), Comment: newPullRequestComment(event.Comment)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported webhook event %q", eventType)
+	}
+}
@@ -0,0 +1,51 @@
+package gitclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebhookEvent_Review(t *testing.T) {
+	payload := []byte(`{
+		"action": "submitted",
+		"review": {"id": 7, "user": {"id": 11, "login": "reviewer1"}, "state": "approved", "submitted_at": "2025-01-06T10:00:00Z"},
+		"pull_request": {"number": 3, "title": "PR 3", "state": "open", "user": {"login": "contributor1"}, "created_at": "2025-01-06T08:00:00Z", "labels": [{"name": "bug"}]},
+		"repository": {"full_name": "owner/repo"}
+	}`)
+
+	event, err := ParseWebhookEvent(WebhookEventReview, payload)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "submitted", event.Action)
+	assert.Equal(t, "owner/repo", event.Repo)
+	assert.Equal(t, 3, event.PullRequest.Number)
+	assert.Equal(t, []string{"bug"}, event.PullRequest.Labels)
+	assert.Equal(t, int64(7), event.Review.ID)
+	assert.Equal(t, ReviewStateApproved, event.Review.State)
+	assert.Equal(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC), event.Review.SubmittedAt.UTC())
+	assert.Nil(t, event.Comment)
+}
+
+func TestParseWebhookEvent_ReviewComment(t *testing.T) {
+	payload := []byte(`{
+		"action": "created",
+		"comment": {"id": 101, "pull_request_review_id": 7, "user": {"id": 11, "login": "reviewer1"}, "path": "a.go", "body": "nit: naming", "created_at": "2025-01-06T09:55:00Z"},
+		"pull_request": {"number": 3, "title": "PR 3", "state": "open", "user": {"login": "contributor1"}, "created_at": "2025-01-06T08:00:00Z"},
+		"repository": {"full_name": "owner/repo"}
+	}`)
+
+	event, err := ParseWebhookEvent(WebhookEventReviewComment, payload)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), event.Comment.PullRequestReviewID)
+	assert.Equal(t, "a.go", *event.Comment.Path)
+	assert.Nil(t, event.Review)
+}
+
+func TestParseWebhookEvent_Unsupported(t *testing.T) {
+	_, err := ParseWebhookEvent("push", []byte(`{}`))
+
+	assert.ErrorContains(t, err, `unsupported webhook event "push"`)
+}
@@ -1,56 +1,593 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"src/gitclient"
 	"src/metrics"
+	"src/report"
+	"src/storage"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
+// Repository names a repository to analyze
+type Repository struct {
+	Owner string
+	Name  string
+}
+
+// Config holds the parsed command-line parameters
+type Config struct {
+	Token     string
+	Timeout   time.Duration
+	UseSearch bool
+	Order     string
+
+	MinRemaining      int
+	WaitForReset      bool
+	RequestsPerSecond float64
+	MaxPages          int
+	RepoConcurrency   int // Repositories analyzed at the same time, sharing the client and its rate limiter
+
+	ProxyURL string
+	CABundle string
+
+	Owner     string
+	Repos     []Repository
+	Org       string
+	DateFrom  time.Time
+	DateTo    time.Time
+	Label     string
+	GroupBy   string
+	Check     bool
+	Format    string
+	Fields    []string
+	Precision int
+	OutputDir string
+	OutputAll bool
+	SQLite    string
+	Cache     string
+	Manifest  string
+
+	SessionAcrossReviews bool
+	SessionGapPercentile float64
+	SLA                  time.Duration
+	FailIfSLABreachRate  float64 // Share of the reviews breaching the SLA above which the run fails, zero disables it
+
+	InstantApprovalThreshold time.Duration
+	IncludeClosedUnmerged    bool
+	PerCommentDuration       time.Duration
+	Authors                  bool
+	FromReadyForReview       bool
+	Delegation               bool
+	RequestToMerge           bool
+	Acknowledgements         bool
+	IssueComments            bool
+	DetectCoAuthored         bool
+	ExcludeCoAuthored        bool
+	CoAuthorTrailers         bool
+	EditedAfterSubmit        time.Duration
+	NitPrefixes              []string
+	ClassifyComments         bool
+	Scope                    string
+	SkipWeekends             bool
+	DecayHalfLife            time.Duration
+	RedactTitles             bool
+	ExcludeApps              bool
+	AuthorAssociations       []string
+	ShowExcluded             bool
+	CoverageChangedFiles     bool
+	ReviewCoverage           bool
+	ThreadResolution         bool
+	IncludeMergeQueue        bool
+	MinChangedLines          int
+	MaxChangedLines          int
+	ExcludePRs               []metrics.PullRequestRef
+	Location                 *time.Location
+	UserLocations            map[string]*time.Location
+
+	RepoFilter  gitclient.RepoFilter
+	Teams       []string
+	IncludeZero bool
+
+	MinSampleSize int
+
+	Baseline       string
+	DeltaThreshold float64
+
+	Leaderboard  bool
+	ScoreWeights metrics.ScoreWeights
+}
+
 func main() {
 	// Parse command-line parameters
-	token, owner, repo, dateFrom, dateTo := ParseFlags()
+	config := ParseFlags()
+
+	// Cancel the run on Ctrl-C or termination, keeping the results calculated so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Get the GitHub client
-	client, err := gitclient.NewGitHubClient(token)
+	client, err := gitclient.NewGitHubClient(config.Token, gitclient.ClientOptions{
+		Timeout:           config.Timeout,
+		UseSearch:         config.UseSearch,
+		Order:             config.Order,
+		MinRemaining:      config.MinRemaining,
+		WaitForReset:      config.WaitForReset,
+		RequestsPerSecond: config.RequestsPerSecond,
+		MaxPages:          config.MaxPages,
+		ProxyURL:          config.ProxyURL,
+		CABundle:          config.CABundle,
+	})
 	if err != nil {
 		log.Fatal(err.Error())
 		return
 	}
 
-	// Calculate the metrics based on date range
-	results, errs := metrics.CalculateMetrics(client, owner, repo, dateFrom, dateTo)
-	if len(errs) > 0 {
-		for _, err := range errs {
+	if config.Check {
+		if err := runCheck(client); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	// Analyze all repositories of the organization, sharing the client and its rate limit tracking
+	if config.Org != "" {
+		repos, err := client.GetOrgRepos(config.Org, config.RepoFilter)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		if len(repos) == 0 {
+			log.Fatalf("Error: Organization %s has no repositories to analyze", config.Org)
+		}
+		log.Printf("Analyzing %d repositories of %s\n", len(repos), config.Org)
+
+		config.Owner = config.Org
+		config.Repos = make([]Repository, len(repos))
+		for i, repo := range repos {
+			config.Repos[i] = Repository{Owner: config.Org, Name: repo}
+		}
+	}
+
+	// Record how the results were made once the run is done, with the repositories final by now. Failed runs exit
+	// without it.
+	defer writeManifest(config, client)
+
+	options := metrics.Options{
+		Label:                    config.Label,
+		IncludeMergeQueue:        config.IncludeMergeQueue,
+		MinChangedLines:          config.MinChangedLines,
+		MaxChangedLines:          config.MaxChangedLines,
+		ExcludePRs:               config.ExcludePRs,
+		SessionAcrossReviews:     config.SessionAcrossReviews,
+		SessionGapPercentile:     config.SessionGapPercentile,
+		SLA:                      config.SLA,
+		InstantApprovalThreshold: config.InstantApprovalThreshold,
+		PerCommentDuration:       config.PerCommentDuration,
+		FromReadyForReview:       config.FromReadyForReview,
+		Delegation:               config.Delegation,
+		RequestToMerge:           config.RequestToMerge,
+		Acknowledgements:         config.Acknowledgements,
+		IssueComments:            config.IssueComments,
+		DetectCoAuthored:         config.DetectCoAuthored,
+		ExcludeCoAuthored:        config.ExcludeCoAuthored,
+		CoAuthorTrailers:         config.CoAuthorTrailers,
+		EditedAfterSubmit:        config.EditedAfterSubmit,
+		NitPrefixes:              config.NitPrefixes,
+		Scope:                    config.Scope,
+		SkipWeekends:             config.SkipWeekends,
+		DecayHalfLife:            config.DecayHalfLife,
+		DecayFrom:                config.DateTo,
+		RedactTitles:             config.RedactTitles,
+		ExcludeApps:              config.ExcludeApps,
+		AuthorAssociations:       config.AuthorAssociations,
+		ChangedFiles:             config.CoverageChangedFiles,
+		ReviewCoverage:           config.ReviewCoverage,
+		ThreadResolution:         config.ThreadResolution,
+		Location:                 config.Location,
+		UserLocations:            config.UserLocations,
+	}
+
+	// Categorize the comments by keywords
+	if config.ClassifyComments {
+		options.CommentClassifier = metrics.KeywordClassifier
+	}
+
+	// Look up the team members to tell in-team from cross-team reviews
+	if len(config.Teams) > 0 {
+		options.Teams = make(map[string][]string, len(config.Teams))
+		for _, team := range config.Teams {
+			members, err := client.GetTeamMembers(config.Owner, team)
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			options.Teams[team] = members
+		}
+	}
+
+	// Describe the run in the reports
+	repoNames := make([]string, len(config.Repos))
+	for i, repo := range config.Repos {
+		repoNames[i] = repo.Owner + "/" + repo.Name
+	}
+	reportOptions := report.Options{
+		Fields:      config.Fields,
+		Precision:   &config.Precision,
+		Repo:        strings.Join(repoNames, ","),
+		DateFrom:    config.DateFrom,
+		DateTo:      config.DateTo,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	if config.Authors {
+		// Calculate the metrics of the pull request authors, merging the repositories
+		all := make([]map[string]*metrics.AuthorMetrics, len(config.Repos))
+		forEachRepo(ctx, config.Repos, config.RepoConcurrency, func(i int, repo Repository) {
+			results, errs := metrics.CalculateAuthorMetrics(ctx, client, repo.Owner, repo.Name, config.DateFrom, config.DateTo, options)
+			exitOnErrors(errs)
+
+			all[i] = results
+		})
+
+		// Output the results
+		if err := report.WriteAuthors(os.Stdout, config.Format, metrics.MergeAuthors(all...)); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	if config.IncludeClosedUnmerged {
+		// Calculate the metrics of merged and closed-unmerged pull requests separately, merging the repositories
+		results := calculateGrouped(ctx, config, func(repo Repository) (map[string]map[string]*metrics.ContributorMetrics, []error) {
+			return metrics.CalculateMetricsByMergedState(ctx, client, repo.Owner, repo.Name, config.DateFrom, config.DateTo, options)
+		})
+
+		// Output the results
+		if err := report.WriteGrouped(os.Stdout, config.Format, results, reportOptions); err != nil {
 			log.Fatal(err.Error())
 		}
+		return
+	}
+
+	switch config.GroupBy {
+	case "label":
+		// Calculate the metrics for every label, merging the repositories
+		results := calculateGrouped(ctx, config, func(repo Repository) (map[string]map[string]*metrics.ContributorMetrics, []error) {
+			return metrics.CalculateMetricsByLabel(ctx, client, repo.Owner, repo.Name, config.DateFrom, config.DateTo, options)
+		})
+
+		// Output the results
+		if err := report.WriteGrouped(os.Stdout, config.Format, results, reportOptions); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	case "month":
+		// Calculate the metrics for every contributor and month, merging the repositories
+		results := calculateGrouped(ctx, config, func(repo Repository) (map[string]map[string]*metrics.ContributorMetrics, []error) {
+			return metrics.CalculateMetricsByMonth(ctx, client, repo.Owner, repo.Name, config.DateFrom, config.DateTo, options)
+		})
 
+		// Output the results
+		if err := report.WriteByContributor(os.Stdout, config.Format, results, reportOptions); err != nil {
+			log.Fatal(err.Error())
+		}
 		return
 	}
 
-	// Log the results
-	logResults(results)
+	// Only fetch the pull requests updated since the previous run
+	var caches map[string]*metrics.Cache
+	if config.Cache != "" {
+		caches, err = storage.LoadCaches(config.Cache)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	// The caches of all repositories exist before they are calculated at the same time
+	if caches != nil {
+		for _, repo := range config.Repos {
+			if key := repo.Owner + "/" + repo.Name; caches[key] == nil {
+				caches[key] = &metrics.Cache{}
+			}
+		}
+	}
+
+	// Calculate the metrics based on date range
+	repoResults := make([]map[string]*metrics.ContributorMetrics, len(config.Repos))
+	repoExcluded := make([]map[string]string, len(config.Repos))
+	forEachRepo(ctx, config.Repos, config.RepoConcurrency, func(i int, repo Repository) {
+		repoResults[i], repoExcluded[i] = calculateRepoMetrics(ctx, client, config, options, repo, caches[repo.Owner+"/"+repo.Name])
+	})
+
+	// Report the repositories in the given order, leaving out the ones not started before the run was cancelled
+	reports := make([]report.RepoReport, 0, len(config.Repos))
+	efficiency := report.Efficiency{CacheEnabled: caches != nil}
+	for i, repo := range config.Repos {
+		if repoResults[i] == nil {
+			continue
+		}
+
+		reports = append(reports, report.RepoReport{Owner: repo.Owner, Repo: repo.Name, Metrics: repoResults[i]})
+		if caches != nil {
+			efficiency.CachedPullRequests += caches[repo.Owner+"/"+repo.Name].Hits
+		}
+	}
+
+	if caches != nil {
+		if err := storage.SaveCaches(config.Cache, caches); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	// Keep the results of the run for the analysis across runs
+	if config.SQLite != "" {
+		if err := saveRuns(config, reports); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	all := make([]map[string]*metrics.ContributorMetrics, len(reports))
+	for i, repoReport := range reports {
+		all[i] = repoReport.Metrics
+	}
+	merged := metrics.Merge(all...)
+	metrics.MarkLowConfidence(merged, config.MinSampleSize)
+
+	// Output the results
+	if config.OutputDir != "" {
+		err = report.WriteDir(config.OutputDir, config.Format, reports, config.OutputAll, reportOptions)
+	} else if config.Baseline != "" {
+		err = writeDelta(config, merged, reportOptions)
+	} else if config.Leaderboard {
+		err = report.WriteLeaderboard(os.Stdout, config.Format, metrics.Leaderboard(merged, config.ScoreWeights), reportOptions)
+	} else {
+		err = report.Write(os.Stdout, config.Format, merged, reportOptions)
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if config.ShowExcluded {
+		if err := report.WriteExcluded(os.Stdout, config.Format, excludedReviewers(merged, repoExcluded...)); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	efficiency.APICalls = client.GetApiRateUsed()
+	efficiency.PullRequests = client.GetPullRequestsFetched()
+	efficiency.APICallsByOperation = client.GetApiCallsByOperation()
+	if err := report.WriteEfficiency(os.Stdout, config.Format, efficiency, reportOptions); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// Fail CI runs once the reviews breach the SLA too often, after the results are out
+	if code := slaBreachExitCode(merged, config.SLA, config.FailIfSLABreachRate); code != 0 {
+		writeManifest(config, client) // os.Exit skips the deferred calls
+		os.Exit(code)
+	}
 }
 
 // ParseFlags handles the parsing of command-line flags
-func ParseFlags() (string, string, string, time.Time, time.Time) {
+func ParseFlags() Config {
 	token := flag.String("token", "", "GitHub access token")
-	owner := flag.String("owner", "", "Repository owner (GitHub username or organization)")
-	repo := flag.String("repo", "", "Repository name")
-	dateFromFlag := flag.String("dateFrom", "", "Start date in YYYY-MM-DD format (required)")
-	dateToFlag := flag.String("dateTo", "", "End date in YYYY-MM-DD format (optional, defaults to today)")
+	owner := flag.String("owner", "", "Repository owner (GitHub username or organization), inferred from the origin remote if omitted together with repo")
+	repo := flag.String("repo", "", "Repository name, or a comma-separated list of repository names, inferred from the origin remote if omitted together with owner")
+	dateFromFlag := flag.String("dateFrom", "", "Start date in YYYY-MM-DD or RFC3339 format (required)")
+	dateToFlag := flag.String("dateTo", "", "End date in YYYY-MM-DD or RFC3339 format (optional, defaults to today)")
+	label := flag.String("label", "", "Only include pull requests carrying this label (optional)")
+	includeMergeQueue := flag.Bool("include-merge-queue", false, "Include the pull requests created by GitHub merge queues, left out by default (optional)")
+	minChangedLines := flag.Int("min-changed-lines", 0, "Only include pull requests changing at least this many lines, added and deleted, at one more API request per PR (optional)")
+	maxChangedLines := flag.Int("max-changed-lines", 0, "Only include pull requests changing at most this many lines, added and deleted, at one more API request per PR (optional)")
+	excludePRList := flag.String("exclude-prs", "", "Comma-separated pull requests to leave out as owner/repo#N, e.g. migrations or bulk reformats, or their bare numbers with a single repository (optional)")
+	groupBy := flag.String("group-by", "", "Group the results: 'label' or 'month' (optional)")
+	timeout := flag.Duration("timeout", gitclient.DefaultTimeout, "Timeout of a single GitHub API request (optional)")
+	check := flag.Bool("check", false, "Verify the token and print the remaining API quota, then exit")
+	format := flag.String("format", report.FormatText, "Output format: 'text', 'json' or 'yaml', which has the durations in seconds (optional)")
+	outputDir := flag.String("output-dir", "", "Write one report file per repository into this directory (optional)")
+	outputAll := flag.Bool("output-all", false, "With output-dir, also write the merged results of all repositories to an 'all' file")
+	sessionAcrossReviews := flag.Bool("session-across-reviews", false, "Compute review sessions across all review rounds of a reviewer on a PR")
+	sessionGapPercentile := flag.Float64("session-gap-percentile", 0, "Derive each reviewer's session gap from this percentile of the intervals between their comments over all PRs in the range, e.g. 90, instead of the fixed 30m (optional)")
+	sla := flag.Duration("sla", 0, "First review SLA, e.g. 24h, used for the SLA compliance rate (optional)")
+	failIfSLABreachRate := flag.Float64("fail-if-sla-breach-rate", 0, fmt.Sprintf("Exit with code %d when more than this share of the reviews of the team or of any reviewer breached the SLA, e.g. 0.2, requires sla (optional)", exitCodeSLABreached))
+	instantApprovalThreshold := flag.Duration("instant-approval-threshold", 0, "Count approvals without comments submitted faster than this after the review request, e.g. 30s, as instant approvals (optional)")
+	leaderboard := flag.Bool("leaderboard", false, "Output a leaderboard ranked by the composite reviewer score instead of the metrics")
+	weightPRs := flag.Float64("weight-prs", metrics.DefaultScoreWeights.PRsReviewed, "Leaderboard weight of PRs reviewed, normalized to the top reviewer")
+	weightDensity := flag.Float64("weight-density", metrics.DefaultScoreWeights.CommentDensity, "Leaderboard weight of comment density, normalized to the top reviewer")
+	weightSLA := flag.Float64("weight-sla", metrics.DefaultScoreWeights.SLACompliance, "Leaderboard weight of the SLA compliance rate")
+	includeClosedUnmerged := flag.Bool("include-closed-unmerged", false, "Report merged, closed-unmerged and open pull requests as separate groups")
+	sqlite := flag.String("sqlite", "", "Also store the per-repository results of the run in this SQLite database (optional)")
+	perCommentDuration := flag.Duration("per-comment-time", 0, "Estimated time per comment, e.g. 2m, for reviews whose comments were all posted at submission (optional)")
+	authors := flag.Bool("authors", false, "Output how quickly the pull request authors respond to review comments instead of the reviewer metrics")
+	baseline := flag.String("baseline", "", "Compare the results with a baseline previously written with format json, or yaml with a .yaml extension, and output only the changes (optional)")
+	deltaThreshold := flag.Float64("delta-threshold", 0, "With baseline, only output contributors with a metric changed by more than this fraction of its baseline value, e.g. 0.1")
+	manifest := flag.String("manifest", "", "Write a JSON manifest of the run to this path, with the tool version, the resolved parameters and the API calls (optional)")
+	cache := flag.String("cache", "", "JSON file caching the fetched PRs, so repeated runs only fetch the PRs updated since (optional, reuse with the same options)")
+	reposFile := flag.String("repos-file", "", "File listing owner/repo pairs to analyze, one per line, # starts a comment (optional, instead of owner and repo)")
+	org := flag.String("org", "", "Analyze all repositories of this organization instead of owner and repo (optional)")
+	repoConcurrency := flag.Int("repo-concurrency", 1, "Repositories analyzed at the same time with several of them, sharing the API rate limit and max-rps (optional)")
+	includeArchived := flag.Bool("include-archived", false, "With org, also analyze the archived repositories")
+	includeForks := flag.Bool("include-forks", false, "With org, also analyze the forked repositories")
+	teams := flag.String("teams", "", "Comma-separated slugs of the owner's teams, used for the cross-team review share (optional)")
+	includeZero := flag.Bool("include-zero", false, "Include the members of the teams who did no reviews in the range, with all metrics zero")
+	minSampleSize := flag.Int("min-sample-size", 0, "Mark the contributors who reviewed fewer PRs than this as low confidence, e.g. 5 (optional)")
+	useSearch := flag.Bool("use-search", false, "Fetch only the pull requests in the date range through the search API, listing them if search is unavailable")
+	order := flag.String("order", gitclient.OrderNewestFirst, "Process the PRs newest first (desc) or oldest first (asc)")
+	fromReadyForReview := flag.Bool("from-ready-for-review", false, "Measure the time to first review from when a draft was marked ready for review instead of from creation")
+	delegation := flag.Bool("delegation", false, "Fetch the timeline of every PR to count review requests completed by someone else than the requested reviewer")
+	requestToMerge := flag.Bool("request-to-merge", false, "Fetch the timeline of every merged PR to measure the time from requesting each reviewer to merging")
+	acknowledgements := flag.Bool("acknowledgements", false, "Fetch comment reactions to count the comments the author acknowledged with a reaction")
+	issueComments := flag.Bool("issue-comments", false, "Also time the reviews by the reviewers' comments in the general discussion of every PR, at one more API request per PR (optional)")
+	detectCoAuthored := flag.Bool("detect-co-authored", false, "Fetch the commits of every PR to count the PRs reviewers also committed to")
+	excludeCoAuthored := flag.Bool("exclude-co-authored", false, "Leave PRs the reviewer also committed to out of their review metrics, implies detect-co-authored")
+	coAuthorTrailers := flag.Bool("co-author-trailers", false, "Fetch the commits of every PR and leave out the reviews of the co-authors named in Co-authored-by trailers like self-reviews")
+	editedAfterSubmit := flag.Duration("edited-after-submit", 0, "Count reviews edited later than this after submission, e.g. 72h (optional, uses the GraphQL API)")
+	classifyComments := flag.Bool("classify-comments", false, "Count the comments of every reviewer by category, e.g. questions, praise, blocking or nits, told by keywords (optional)")
+	nitPrefixList := flag.String("nit-prefixes", "nit:,nit ", "Comma-separated prefixes marking trivial comments, matched ignoring case (optional, empty disables the classification)")
+	scope := flag.String("scope", metrics.ScopeCreated, "Include PRs created in the range (created) or reviews submitted in the range (review-date)")
+	skipWeekends := flag.Bool("skip-weekends", false, "Leave Saturdays and Sundays out of the time to first review, in the reviewer's timezone")
+	decayHalfLife := flag.Duration("decay-half-life", 0, "Also report PRs reviewed, comments and time to first review weighted by recency, halving every half-life before dateTo, e.g. 720h (optional)")
+	redactTitles := flag.Bool("redact-titles", false, "Log the PRs by number instead of title while processing them")
+	excludeApps := flag.Bool("exclude-apps", false, "Leave out the reviews of GitHub Apps and Actions, identified by their Bot account type")
+	associations := flag.String("associations", "", "Comma-separated relationships of the reviewers to the repository to include, e.g. OWNER,MEMBER (optional, defaults to all)")
+	showExcluded := flag.Bool("show-excluded", false, "List the reviewers left out by exclude-apps or associations or below min-sample-size, and why, in a separate section of the text output")
+	coverageChangedFiles := flag.Bool("coverage-changed-files", false, "Count reviewers in the file reviewer coverage for all files changed by the PRs they reviewed, not only the ones they commented on, at one more API request per PR (optional)")
+	reviewCoverage := flag.Bool("review-coverage", false, "Report the share of the changed hunks every reviewer commented on, at one more API request per PR (optional)")
+	threadResolution := flag.Bool("thread-resolution", false, "Count the changes requests with all their review threads resolved as resolved even without the reviewer's approval, at one more API request per PR (optional)")
+	timezone := flag.String("timezone", "UTC", "IANA timezone of the reviews by hour, e.g. Europe/Berlin (optional)")
+	userTimezones := flag.String("user-timezones", "", "Comma-separated login=timezone pairs overriding timezone for individual reviewers, e.g. alice=Asia/Tokyo (optional)")
+	fieldNames := flag.String("fields", "", "Comma-separated metrics to include in the text output, e.g. PRsReviewed,TotalComments (optional, defaults to all)")
+	precision := flag.Int("precision", report.DefaultPrecision, "Decimal places of the averages and rates in the text output (optional, the JSON output keeps full precision)")
+	minRemaining := flag.Int("min-remaining", 0, "Stop, or wait with wait-for-reset, when the remaining API rate limit drops below this floor, e.g. 100 (optional)")
+	waitForReset := flag.Bool("wait-for-reset", false, "Wait until the API rate limit resets instead of stopping with the results calculated so far")
+	requestsPerSecond := flag.Float64("max-rps", 0, "Upper bound of GitHub API requests per second, e.g. 5 (optional, defaults to spreading the remaining rate limit until its reset)")
+	maxPages := flag.Int("max-pages", gitclient.DefaultMaxPages, "Pages fetched by a single paginated API call at most, stopping pagination that doesn't end (optional)")
+	proxyURL := flag.String("proxy", "", "Proxy for the GitHub API requests, e.g. http://proxy.corp:3128 (optional, defaults to HTTPS_PROXY)")
+	caBundle := flag.String("ca-bundle", "", "PEM file of additional certificates to trust, e.g. of a TLS-inspecting proxy (optional)")
 
 	flag.Parse()
 
-	if *token == "" || *owner == "" || *repo == "" || *dateFromFlag == "" {
-		log.Fatal("Error: All parameters (token, owner, repo, and dateFrom) are required")
+	if *check {
+		if *token == "" {
+			log.Fatal("Error: Parameter token is required")
+		}
+		return Config{
+			Token:   *token,
+			Timeout: *timeout,
+
+			MinRemaining:      *minRemaining,
+			WaitForReset:      *waitForReset,
+			RequestsPerSecond: *requestsPerSecond,
+			MaxPages:          *maxPages,
+
+			ProxyURL: *proxyURL,
+			CABundle: *caBundle,
+
+			Check: true,
+		}
+	}
+
+	if *org != "" && (*owner != "" || *repo != "") {
+		log.Fatal("Error: Parameter org can't be combined with owner or repo")
+	}
+
+	if *reposFile != "" && (*org != "" || *owner != "" || *repo != "") {
+		log.Fatal("Error: Parameter repos-file can't be combined with org, owner or repo")
+	}
+
+	if *reposFile != "" && *teams != "" {
+		log.Fatal("Error: Parameter teams requires owner or org to look the teams up in")
+	}
+
+	// Infer the repository from the git checkout when it's not specified
+	if *org == "" && *reposFile == "" && *owner == "" && *repo == "" {
+		if detectedOwner, detectedRepo, err := detectRepository(); err == nil {
+			*owner, *repo = detectedOwner, detectedRepo
+			log.Printf("Using repository %s/%s from the origin remote\n", *owner, *repo)
+		}
+	}
+
+	// The owner may come with the repository, as owner/repo or a URL
+	if *token == "" || (*org == "" && *reposFile == "" && (*repo == "" || (*owner == "" && !strings.Contains(*repo, "/")))) || *dateFromFlag == "" {
+		log.Fatal("Error: All parameters (token, owner and repo, org or repos-file, and dateFrom) are required")
+	}
+
+	if *groupBy != "" && *groupBy != "label" && *groupBy != "month" {
+		log.Fatalf("Error: Invalid value for 'group-by'. Supported values: label, month")
+	}
+
+	if *scope != metrics.ScopeCreated && *scope != metrics.ScopeReviewDate {
+		log.Fatalf("Error: Invalid value for 'scope'. Supported values: created, review-date")
+	}
+
+	if *order != gitclient.OrderNewestFirst && *order != gitclient.OrderOldestFirst {
+		log.Fatalf("Error: Invalid value for 'order'. Supported values: desc, asc")
+	}
+
+	if !report.IsSupportedFormat(*format) {
+		log.Fatalf("Error: Invalid value for 'format'. Supported values: text, json, yaml")
+	}
+
+	if *outputDir != "" && *groupBy != "" {
+		log.Fatal("Error: Parameters output-dir and group-by can't be combined")
+	}
+
+	if *leaderboard && (*outputDir != "" || *groupBy != "") {
+		log.Fatal("Error: Parameter leaderboard can't be combined with output-dir or group-by")
+	}
+
+	if *authors && (*outputDir != "" || *groupBy != "" || *leaderboard || *includeClosedUnmerged || *sqlite != "") {
+		log.Fatal("Error: Parameter authors can't be combined with output-dir, group-by, leaderboard, include-closed-unmerged or sqlite")
+	}
+
+	if *baseline != "" && (*outputDir != "" || *groupBy != "" || *leaderboard || *includeClosedUnmerged) {
+		log.Fatal("Error: Parameter baseline can't be combined with output-dir, group-by, leaderboard or include-closed-unmerged")
+	}
+
+	if *sqlite != "" && (*groupBy != "" || *includeClosedUnmerged) {
+		log.Fatal("Error: Parameter sqlite can't be combined with group-by or include-closed-unmerged")
+	}
+
+	if *includeClosedUnmerged && (*outputDir != "" || *groupBy != "" || *leaderboard) {
+		log.Fatal("Error: Parameter include-closed-unmerged can't be combined with output-dir, group-by or leaderboard")
+	}
+
+	if *cache != "" && (*groupBy != "" || *includeClosedUnmerged || *authors || *scope == metrics.ScopeReviewDate) {
+		log.Fatal("Error: Parameter cache can't be combined with group-by, include-closed-unmerged, authors or scope=review-date")
+	}
+
+	if *includeZero && (*teams == "" || *groupBy != "" || *includeClosedUnmerged || *authors) {
+		log.Fatal("Error: Parameter include-zero requires teams and can't be combined with group-by, include-closed-unmerged or authors")
+	}
+
+	// Closed pull requests found by the search API can't be told apart from merged ones and have no merge time
+	if *includeClosedUnmerged && *useSearch {
+		log.Fatal("Error: Parameters include-closed-unmerged and use-search can't be combined")
+	}
+	if *requestToMerge && *useSearch {
+		log.Fatal("Error: Parameters request-to-merge and use-search can't be combined")
+	}
+
+	// The repositories of an organization are listed once the client is available
+	var repos []Repository
+	if *reposFile != "" {
+		var err error
+		repos, err = parseReposFile(*reposFile)
+		if err != nil {
+			log.Fatalf("Error: Invalid value for 'repos-file'. %v", err)
+		}
+	} else if *org == "" {
+		for _, name := range strings.Split(*repo, ",") {
+			if strings.TrimSpace(name) == "" {
+				log.Fatal("Error: Invalid value for 'repo'. Repository names can't be empty")
+			}
+			repository, err := parseRepository(name, *owner)
+			if err != nil {
+				log.Fatalf("Error: Invalid value for 'repo'. %v", err)
+			}
+			repos = append(repos, repository)
+		}
+
+		// The teams are looked up in the owner of the repositories given as owner/repo
+		if *owner == "" {
+			*owner = repos[0].Owner
+		}
 	}
 
 	// Parse dateFrom
-	dateFrom, err := time.Parse("2006-01-02", *dateFromFlag)
+	dateFrom, err := parseDate(*dateFromFlag, false)
 	if err != nil {
-		log.Fatalf("Error: Invalid value for 'dateFrom'. Please use YYYY-MM-DD format. %v", err)
+		log.Fatalf("Error: Invalid value for 'dateFrom'. Please use YYYY-MM-DD or RFC3339 format. %v", err)
 	}
 
 	// Parse dateTo, default to today if not specified
@@ -59,28 +596,498 @@ func ParseFlags() (string, string, string, time.Time, time.Time) {
 		now := time.Now()
 		dateTo = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, int(time.Second-time.Nanosecond), now.Location())
 	} else {
-		dateTo, err = time.Parse("2006-01-02", *dateToFlag)
+		dateTo, err = parseDate(*dateToFlag, true)
 		if err != nil {
-			log.Fatalf("Error: Invalid value for 'dateTo'. Please use YYYY-MM-DD format. %v", err)
+			log.Fatalf("Error: Invalid value for 'dateTo'. Please use YYYY-MM-DD or RFC3339 format. %v", err)
 		}
-		// Set to end of day for dateTo
-		dateTo = time.Date(dateTo.Year(), dateTo.Month(), dateTo.Day(), 23, 59, 59, int(time.Second-time.Nanosecond), dateTo.Location())
 	}
 
-	return *token, *owner, *repo, dateFrom, dateTo
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		log.Fatalf("Error: Invalid value for 'timezone'. %v", err)
+	}
+
+	userLocations, err := parseUserLocations(*userTimezones)
+	if err != nil {
+		log.Fatalf("Error: Invalid value for 'user-timezones'. %v", err)
+	}
+
+	fields, err := report.ParseFields(*fieldNames)
+	if err != nil {
+		log.Fatalf("Error: Invalid value for 'fields'. %v", err)
+	}
+
+	if *sessionGapPercentile < 0 || *sessionGapPercentile > 100 {
+		log.Fatal("Error: Parameter session-gap-percentile must be between 0 and 100")
+	}
+
+	if *decayHalfLife < 0 {
+		log.Fatal("Error: Parameter decay-half-life can't be negative")
+	}
+
+	if *maxPages <= 0 {
+		log.Fatal("Error: Parameter max-pages must be positive")
+	}
+
+	if *minChangedLines < 0 || *maxChangedLines < 0 {
+		log.Fatal("Error: Parameters min-changed-lines and max-changed-lines can't be negative")
+	}
+	if *maxChangedLines > 0 && *maxChangedLines < *minChangedLines {
+		log.Fatal("Error: Parameter max-changed-lines can't be less than min-changed-lines")
+	}
+
+	excludePRs, err := parsePRRefs(*excludePRList, repos)
+	if err != nil {
+		log.Fatalf("Error: Invalid value for 'exclude-prs'. %v", err)
+	}
+
+	if *failIfSLABreachRate < 0 || *failIfSLABreachRate >= 1 {
+		log.Fatal("Error: Parameter fail-if-sla-breach-rate must be at least 0 and less than 1")
+	}
+	if *failIfSLABreachRate > 0 && (*sla <= 0 || *authors || *groupBy != "" || *includeClosedUnmerged) {
+		log.Fatal("Error: Parameter fail-if-sla-breach-rate requires sla and can't be combined with authors, group-by or include-closed-unmerged")
+	}
+
+	if *showExcluded && (*authors || *groupBy != "" || *includeClosedUnmerged) {
+		log.Fatal("Error: Parameter show-excluded can't be combined with authors, group-by or include-closed-unmerged")
+	}
+
+	if *minSampleSize < 0 {
+		log.Fatal("Error: Parameter min-sample-size can't be negative")
+	}
+
+	if *repoConcurrency < 1 {
+		log.Fatal("Error: Parameter repo-concurrency must be at least 1")
+	}
+
+	if *precision < 0 {
+		log.Fatal("Error: Parameter precision can't be negative")
+	}
+
+	var nitPrefixes []string
+	if *nitPrefixList != "" {
+		nitPrefixes = strings.Split(*nitPrefixList, ",")
+	}
+
+	authorAssociations, err := parseAuthorAssociations(*associations)
+	if err != nil {
+		log.Fatalf("Error: Invalid value for 'associations'. %v", err)
+	}
+
+	var teamSlugs []string
+	if *teams != "" {
+		teamSlugs = strings.Split(*teams, ",")
+		for i := range teamSlugs {
+			teamSlugs[i] = strings.TrimSpace(teamSlugs[i])
+		}
+	}
+
+	return Config{
+		Token:     *token,
+		Timeout:   *timeout,
+		UseSearch: *useSearch,
+		Order:     *order,
+
+		MinRemaining:      *minRemaining,
+		WaitForReset:      *waitForReset,
+		RequestsPerSecond: *requestsPerSecond,
+		MaxPages:          *maxPages,
+
+		ProxyURL: *proxyURL,
+		CABundle: *caBundle,
+
+		Owner:           *owner,
+		Repos:           repos,
+		Org:             *org,
+		RepoConcurrency: *repoConcurrency,
+		DateFrom:        dateFrom,
+		DateTo:          dateTo,
+		Label:           *label,
+		GroupBy:         *groupBy,
+		Format:          *format,
+		Fields:          fields,
+		Precision:       *precision,
+		OutputDir:       *outputDir,
+		OutputAll:       *outputAll,
+		SQLite:          *sqlite,
+		Cache:           *cache,
+		Manifest:        *manifest,
+
+		SessionAcrossReviews: *sessionAcrossReviews,
+		SessionGapPercentile: *sessionGapPercentile,
+		SLA:                  *sla,
+		FailIfSLABreachRate:  *failIfSLABreachRate,
+
+		InstantApprovalThreshold: *instantApprovalThreshold,
+		IncludeClosedUnmerged:    *includeClosedUnmerged,
+		PerCommentDuration:       *perCommentDuration,
+		Authors:                  *authors,
+		FromReadyForReview:       *fromReadyForReview,
+		Delegation:               *delegation,
+		RequestToMerge:           *requestToMerge,
+		Acknowledgements:         *acknowledgements,
+		IssueComments:            *issueComments,
+		DetectCoAuthored:         *detectCoAuthored,
+		ExcludeCoAuthored:        *excludeCoAuthored,
+		CoAuthorTrailers:         *coAuthorTrailers,
+		EditedAfterSubmit:        *editedAfterSubmit,
+		NitPrefixes:              nitPrefixes,
+		ClassifyComments:         *classifyComments,
+		Scope:                    *scope,
+		SkipWeekends:             *skipWeekends,
+		DecayHalfLife:            *decayHalfLife,
+		RedactTitles:             *redactTitles,
+		ExcludeApps:              *excludeApps,
+		AuthorAssociations:       authorAssociations,
+		ShowExcluded:             *showExcluded,
+		CoverageChangedFiles:     *coverageChangedFiles,
+		ReviewCoverage:           *reviewCoverage,
+		ThreadResolution:         *threadResolution,
+		IncludeMergeQueue:        *includeMergeQueue,
+		MinChangedLines:          *minChangedLines,
+		MaxChangedLines:          *maxChangedLines,
+		ExcludePRs:               excludePRs,
+		Location:                 location,
+		UserLocations:            userLocations,
+
+		RepoFilter:  gitclient.RepoFilter{IncludeArchived: *includeArchived, IncludeForks: *includeForks},
+		Teams:       teamSlugs,
+		IncludeZero: *includeZero,
+
+		MinSampleSize: *minSampleSize,
+
+		Baseline:       *baseline,
+		DeltaThreshold: *deltaThreshold,
+
+		Leaderboard:  *leaderboard,
+		ScoreWeights: metrics.ScoreWeights{PRsReviewed: *weightPRs, CommentDensity: *weightDensity, SLACompliance: *weightSLA},
+	}
+}
+
+// parseDate parses a date in YYYY-MM-DD format or a precise RFC3339 timestamp. With endOfDay set, the date-only
+// form is expanded to the last moment of that day; timestamps are always used as given.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			date = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, int(time.Second-time.Nanosecond), date.Location())
+		}
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseUserLocations parses comma-separated login=timezone pairs into the location of every login
+func parseUserLocations(value string) (map[string]*time.Location, error) {
+	locations := make(map[string]*time.Location)
+	if value == "" {
+		return locations, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		login, timezone, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || login == "" {
+			return nil, fmt.Errorf("expected login=timezone, got %q", pair)
+		}
+
+		location, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, err
+		}
+		locations[login] = location
+	}
+
+	return locations, nil
 }
 
-// ...existing code...
+// parseAuthorAssociations parses comma-separated author associations, ignoring case, into the values GitHub reports
+func parseAuthorAssociations(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	supported := map[string]bool{
+		gitclient.AuthorAssociationOwner:                true,
+		gitclient.AuthorAssociationMember:               true,
+		gitclient.AuthorAssociationCollaborator:         true,
+		gitclient.AuthorAssociationContributor:          true,
+		gitclient.AuthorAssociationFirstTimeContributor: true,
+		gitclient.AuthorAssociationFirstTimer:           true,
+		gitclient.AuthorAssociationMannequin:            true,
+		gitclient.AuthorAssociationNone:                 true,
+	}
+
+	var associations []string
+	for _, name := range strings.Split(value, ",") {
+		association := strings.ToUpper(strings.TrimSpace(name))
+		if !supported[association] {
+			return nil, fmt.Errorf("unknown author association %q", name)
+		}
+		associations = append(associations, association)
+	}
+
+	return associations, nil
+}
+
+// excludedReviewers combines the reviewers left out of every repository, leaving out those with reviews in the results,
+// and the reviewers of the results marked as low confidence by min-sample-size
+func excludedReviewers(results map[string]*metrics.ContributorMetrics, excluded ...map[string]string) map[string]string {
+	combined := make(map[string]string)
+	for login, userMetrics := range results {
+		if userMetrics.LowConfidence {
+			combined[login] = metrics.ExclusionBelowMinSample
+		}
+	}
+	for _, repoExcluded := range excluded {
+		for login, reason := range repoExcluded {
+			if _, reviewed := results[login]; !reviewed {
+				combined[login] = reason
+			}
+		}
+	}
+	return combined
+}
+
+// parsePRRefs parses comma-separated pull requests given as owner/repo#N. Bare numbers belong to the only repository
+// of repos; with several repositories, or those of an organization listed later, they are rejected as ambiguous.
+func parsePRRefs(value string, repos []Repository) ([]metrics.PullRequestRef, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var refs []metrics.PullRequestRef
+	for _, field := range strings.Split(value, ",") {
+		name, numberText := "", strings.TrimSpace(field)
+		if i := strings.LastIndex(numberText, "#"); i >= 0 {
+			name, numberText = strings.TrimSpace(numberText[:i]), numberText[i+1:]
+		}
+		number, err := strconv.Atoi(numberText)
+		if err != nil || number <= 0 {
+			return nil, fmt.Errorf("expected a pull request as owner/repo#N, got %q", field)
+		}
+
+		var ref metrics.PullRequestRef
+		if name == "" {
+			if len(repos) != 1 {
+				return nil, fmt.Errorf("pull request %q needs its repository as owner/repo#N when several repositories are analyzed", field)
+			}
+			ref = metrics.PullRequestRef{Repo: repos[0].Owner + "/" + repos[0].Name, Number: number}
+		} else {
+			repository, err := parseRepository(name, "")
+			if err != nil {
+				return nil, err
+			}
+			ref = metrics.PullRequestRef{Repo: repository.Owner + "/" + repository.Name, Number: number}
+		}
+		refs = append(refs, ref)
+	}
 
-// logResults logs the calculated metrics
-func logResults(results map[string]*metrics.ContributorMetrics) {
-	for contributor, metrics := range results {
-		log.Printf("\nContributor: %s\n", contributor)
-		log.Printf("PRs Reviewed: %d\n", metrics.PRsReviewed)
-		log.Printf("Average Comments per Review: %.2f\n", metrics.AverageCommentsPerReview)
-		log.Printf("Average Time to Complete Review: %v\n", metrics.AverageTimeToCompleteReview)
-		log.Printf("Average Time to First Review: %v\n", metrics.AverageTimeToFirstReview)
-		log.Printf("Total Comments: %d\n", metrics.TotalComments)
-		log.Printf("Percentage of Comments Leading to Changes: %.2f%%\n", metrics.PercentageCommentsLeadingToChanges)
+	return refs, nil
+}
+
+// parseReposFile reads the owner/repo pairs listed one per line in the file. Blank lines and lines starting with #
+// are skipped.
+func parseReposFile(path string) ([]Repository, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repository
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		repo, err := parseRepository(line, "")
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		repos = append(repos, repo)
+	}
+
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories listed in %s", path)
+	}
+	return repos, nil
+}
+
+// runCheck logs the authenticated user and the current core rate limit without touching any repository
+func runCheck(client gitclient.GitClient) error {
+	login, err := client.GetAuthenticatedUser()
+	if err != nil {
+		return err
+	}
+
+	status, err := client.GetRateLimitStatus()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Authenticated as: %s\n", login)
+	log.Printf("API rate remaining: %d of %d (resets at %s, in %v)\n", status.Remaining, status.Limit, status.Reset.Format(time.RFC3339), time.Until(status.Reset).Round(time.Second))
+
+	return nil
+}
+
+// writeDelta outputs the changes of the results compared to the baseline file
+func writeDelta(config Config, results map[string]*metrics.ContributorMetrics, options report.Options) error {
+	read := report.ReadJSON
+	if extension := filepath.Ext(config.Baseline); extension == ".yaml" || extension == ".yml" {
+		read = report.ReadYAML
+	}
+
+	baseline, err := read(config.Baseline)
+	if err != nil {
+		return err
+	}
+
+	return report.WriteDelta(os.Stdout, config.Format, metrics.Delta(results, baseline, config.DeltaThreshold), options)
+}
+
+// saveRuns stores the results of every repository as a separate run in the SQLite database
+func saveRuns(config Config, reports []report.RepoReport) error {
+	store, err := storage.Open(config.SQLite)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	createdAt := time.Now()
+	for _, repoReport := range reports {
+		run := storage.Run{Owner: repoReport.Owner, Repo: repoReport.Repo, DateFrom: config.DateFrom, DateTo: config.DateTo, CreatedAt: createdAt}
+		if _, err := store.SaveRun(run, repoReport.Metrics); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// calculateGrouped runs the grouped calculation for every repository and merges the results group by group
+func calculateGrouped(ctx context.Context, config Config, calculate func(repo Repository) (map[string]map[string]*metrics.ContributorMetrics, []error)) map[string]map[string]*metrics.ContributorMetrics {
+	repoResults := make([]map[string]map[string]*metrics.ContributorMetrics, len(config.Repos))
+	forEachRepo(ctx, config.Repos, config.RepoConcurrency, func(i int, repo Repository) {
+		results, errs := calculate(repo)
+		exitOnErrors(errs)
+
+		repoResults[i] = results
+	})
+
+	grouped := make(map[string][]map[string]*metrics.ContributorMetrics)
+	for _, results := range repoResults {
+		for group, groupResults := range results {
+			grouped[group] = append(grouped[group], groupResults)
+		}
+	}
+
+	merged := make(map[string]map[string]*metrics.ContributorMetrics)
+	for group, groupResults := range grouped {
+		merged[group] = metrics.Merge(groupResults...)
+		metrics.MarkLowConfidence(merged[group], config.MinSampleSize)
+	}
+
+	return merged
+}
+
+// forEachRepo calls process for every repository with its index, running up to concurrency of them at a time on the
+// shared client and its rate limiter, and returns once all of them are done. No more repositories start once ctx is
+// done. process must only store its results at the index, so they merge the same whatever order the repositories end in.
+func forEachRepo(ctx context.Context, repos []Repository, concurrency int, process func(i int, repo Repository)) {
+	running := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		running <- struct{}{}
+		if ctx.Err() != nil {
+			<-running
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-running }()
+			process(i, repo)
+		}()
+	}
+	wg.Wait()
+}
+
+// calculateRepoMetrics calculates the metrics of one repository, from the cache when there is one, and returns them
+// with the reviewers left out with show-excluded
+func calculateRepoMetrics(ctx context.Context, client gitclient.GitClient, config Config, options metrics.Options, repo Repository, cache *metrics.Cache) (map[string]*metrics.ContributorMetrics, map[string]string) {
+	if config.ShowExcluded {
+		options.Excluded = make(map[string]string)
+	}
+
+	var results map[string]*metrics.ContributorMetrics
+	var errs []error
+	if cache != nil {
+		results, errs = metrics.CalculateMetricsIncremental(ctx, client, repo.Owner, repo.Name, config.DateFrom, config.DateTo, options, cache)
+	} else {
+		results, errs = metrics.CalculateMetrics(ctx, client, repo.Owner, repo.Name, config.DateFrom, config.DateTo, options)
+	}
+	exitOnErrors(errs)
+
+	// List the team members without reviews too, so the missing reviews show
+	if config.IncludeZero {
+		for _, members := range options.Teams {
+			metrics.AddInactive(results, members)
+		}
+	}
+	metrics.MarkLowConfidence(results, config.MinSampleSize)
+
+	return results, options.Excluded
+}
+
+// Exit code of the runs where the SLA breach rate exceeded fail-if-sla-breach-rate, apart from the failures exiting with 1
+const exitCodeSLABreached = 3
+
+// slaBreachExitCode returns exitCodeSLABreached when more than the threshold share of the reviews of the team, or of
+// any reviewer, breached the SLA, logging who breached it, and 0 otherwise or when the threshold is zero
+func slaBreachExitCode(results map[string]*metrics.ContributorMetrics, sla time.Duration, threshold float64) int {
+	if threshold <= 0 {
+		return 0
+	}
+
+	code := 0
+	if rate := metrics.TeamSLABreachRate(results, sla); rate > threshold {
+		log.Printf("Error: %.0f%% of the reviews of the team breached the SLA of %v, more than %.0f%%\n", rate*100, sla, threshold*100)
+		code = exitCodeSLABreached
+	}
+
+	reviewers := make([]string, 0, len(results))
+	for reviewer := range results {
+		reviewers = append(reviewers, reviewer)
+	}
+	sort.Strings(reviewers)
+	for _, reviewer := range reviewers {
+		// Reviewers without reviews, e.g. listed with include-zero, breached nothing
+		userMetrics := results[reviewer]
+		if userMetrics.PRsReviewed == 0 {
+			continue
+		}
+		if rate := 1 - userMetrics.SLAComplianceRate; rate > threshold {
+			log.Printf("Error: %.0f%% of the reviews of %s breached the SLA of %v, more than %.0f%%\n", rate*100, reviewer, sla, threshold*100)
+			code = exitCodeSLABreached
+		}
+	}
+
+	return code
+}
+
+// exitOnErrors logs the errors and exits if there are any
+func exitOnErrors(errs []error) {
+	if len(errs) > 0 {
+		for _, err := range errs {
+			var metricsErr *metrics.MetricsError
+			if errors.As(err, &metricsErr) {
+				log.Printf("Error: %s failed for PR #%d of %s: %v", metricsErr.Operation, metricsErr.PRNumber, metricsErr.Repo, metricsErr.Err)
+				continue
+			}
+			log.Print(err.Error())
+		}
+		os.Exit(1)
 	}
 }
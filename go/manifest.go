@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/url"
+	"os"
+	"src/gitclient"
+	"src/report"
+	"time"
+)
+
+// Version of the tool, recorded in the run manifests
+const version = "1.0.0"
+
+// Manifest records how a run was made, so that its results can be reproduced and audited
+type Manifest struct {
+	Version       string            `json:"version"`
+	GeneratedAt   time.Time         `json:"generatedAt"`
+	Repos         []string          `json:"repos"` // owner/repo of every analyzed repository
+	Range         report.Range      `json:"range"`
+	Timezone      string            `json:"timezone"`
+	UserTimezones map[string]string `json:"userTimezones"`
+	Exclusions    Exclusions        `json:"exclusions"`
+	Flags         map[string]string `json:"flags"` // Raw value or default of every flag, the token and the proxy credentials redacted
+
+	APICalls            int            `json:"apiCalls"`
+	APICallsByOperation map[string]int `json:"apiCallsByOperation"`
+}
+
+// Exclusions lists what the run left out of the metrics
+type Exclusions struct {
+	Label              string   `json:"label"`              // Only pull requests carrying it were included, empty for all
+	MergeQueue         bool     `json:"mergeQueue"`         // Pull requests of merge queues were left out
+	MinChangedLines    int      `json:"minChangedLines"`    // Smaller pull requests were left out, zero for no bound
+	MaxChangedLines    int      `json:"maxChangedLines"`    // Larger pull requests were left out, zero for no bound
+	PRs                []string `json:"prs"`                // Pull requests left out, as owner/repo#N
+	Apps               bool     `json:"apps"`               // Reviews of GitHub Apps and Actions were left out
+	CoAuthored         bool     `json:"coAuthored"`         // Pull requests were left out of the metrics of reviewers who committed to them
+	AuthorAssociations []string `json:"authorAssociations"` // Only the reviewers with these relationships were included, empty for all
+}
+
+// Value the token is recorded with, keeping it out of the manifest
+const redactedToken = "REDACTED"
+
+// newManifest describes the run of the configuration with the flags it was parsed from and its API calls
+func newManifest(config Config, flags *flag.FlagSet, apiCalls map[string]int, generatedAt time.Time) Manifest {
+	manifest := Manifest{
+		Version:       version,
+		GeneratedAt:   generatedAt,
+		Repos:         make([]string, len(config.Repos)),
+		Range:         report.Range{From: config.DateFrom, To: config.DateTo},
+		UserTimezones: make(map[string]string, len(config.UserLocations)),
+		Exclusions: Exclusions{
+			Label:              config.Label,
+			MergeQueue:         !config.IncludeMergeQueue,
+			MinChangedLines:    config.MinChangedLines,
+			MaxChangedLines:    config.MaxChangedLines,
+			Apps:               config.ExcludeApps,
+			CoAuthored:         config.ExcludeCoAuthored,
+			AuthorAssociations: config.AuthorAssociations,
+		},
+		Flags:               make(map[string]string),
+		APICallsByOperation: apiCalls,
+	}
+
+	for i, repo := range config.Repos {
+		manifest.Repos[i] = repo.Owner + "/" + repo.Name
+	}
+	for _, ref := range config.ExcludePRs {
+		manifest.Exclusions.PRs = append(manifest.Exclusions.PRs, ref.String())
+	}
+	if config.Location != nil {
+		manifest.Timezone = config.Location.String()
+	}
+	for login, location := range config.UserLocations {
+		manifest.UserTimezones[login] = location.String()
+	}
+
+	flags.VisitAll(func(f *flag.Flag) {
+		manifest.Flags[f.Name] = f.Value.String()
+	})
+	if manifest.Flags["token"] != "" {
+		manifest.Flags["token"] = redactedToken
+	}
+	if manifest.Flags["proxy"] != "" {
+		manifest.Flags["proxy"] = redactUserinfo(manifest.Flags["proxy"])
+	}
+
+	for _, calls := range apiCalls {
+		manifest.APICalls += calls
+	}
+
+	return manifest
+}
+
+// Returns the URL without its user name and password, or redactedToken when it can't be parsed
+func redactUserinfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redactedToken
+	}
+	u.User = nil
+	return u.String()
+}
+
+// writeManifest writes the manifest of the run to config.Manifest, doing nothing without it. Runs failing with
+// log.Fatal or exitOnErrors exit without running the deferred call, so only completed runs leave a manifest.
+func writeManifest(config Config, client gitclient.GitClient) {
+	if config.Manifest == "" {
+		return
+	}
+
+	manifest := newManifest(config, flag.CommandLine, client.GetApiCallsByOperation(), time.Now().UTC())
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if err := os.WriteFile(config.Manifest, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("Error: Failed to write the manifest. %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"src/gitclient"
+)
+
+// AuthorMetrics describes how pull request authors respond to the review comments they receive
+type AuthorMetrics struct {
+	PRsAuthored               int
+	CommentsResponded         int           // Reviewer comments followed by a comment or a commit of the author
+	AverageAuthorResponseTime time.Duration // From a reviewer comment to the next comment or commit of the author
+
+	// From opening the PR to its first commit made after that, which asks the reviewers to look again, averaged over
+	// the PRs with such a commit. The commits are only fetched for the PRs with review comments.
+	AverageTimeToFirstCommit time.Duration
+
+	// Running sums preserved so that results can be merged before the averages are recomputed
+	totalResponseTime      time.Duration
+	firstCommitPRs         int
+	totalTimeToFirstCommit time.Duration
+}
+
+// Adds the running sums and counts of other to m. Averages must be recomputed afterwards.
+func (m *AuthorMetrics) add(other *AuthorMetrics) {
+	m.PRsAuthored += other.PRsAuthored
+	m.CommentsResponded += other.CommentsResponded
+	m.totalResponseTime += other.totalResponseTime
+	m.firstCommitPRs += other.firstCommitPRs
+	m.totalTimeToFirstCommit += other.totalTimeToFirstCommit
+}
+
+// CalculateAuthorMetrics calculates the metrics of the pull request authors in the date range.
+func CalculateAuthorMetrics(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options) (map[string]*AuthorMetrics, []error) {
+	metrics := make(map[string]*AuthorMetrics)
+
+	errs := forEachPullRequest(ctx, client, owner, repo, dateFrom, dateTo, options, func(data *pullRequestData) {
+		reduceAuthorMetrics(metrics, calculateAuthorPullRequestMetrics(data))
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	finalizeAuthorMetrics(metrics)
+
+	return metrics, nil
+}
+
+// MergeAuthors combines several author results into one, recomputing the averages from the underlying sums.
+// The inputs are not modified.
+func MergeAuthors(results ...map[string]*AuthorMetrics) map[string]*AuthorMetrics {
+	merged := make(map[string]*AuthorMetrics)
+
+	for _, metrics := range results {
+		reduceAuthorMetrics(merged, metrics)
+	}
+
+	finalizeAuthorMetrics(merged)
+
+	return merged
+}
+
+// Calculates the partial author metrics of a single pull request. Every comment of a reviewer is matched with the
+// author's first comment or commit after it.
+func calculateAuthorPullRequestMetrics(data *pullRequestData) map[string]*AuthorMetrics {
+	author := *data.pr.UserLogin
+	authorMetrics := &AuthorMetrics{PRsAuthored: 1}
+	commits := getAuthorCommits(data.commits, data.pr)
+
+	// Times the author acted on the pull request
+	var responses []time.Time
+	for _, comment := range data.comments {
+		if comment.UserLogin != nil && *comment.UserLogin == author {
+			responses = append(responses, *comment.CreatedAt)
+		}
+	}
+	for _, commit := range commits {
+		responses = append(responses, *commit.CreatedAt)
+	}
+
+	for _, comment := range data.comments {
+		if comment.UserLogin != nil && *comment.UserLogin == author {
+			continue
+		}
+
+		if responseTime, found := getNextResponseTime(responses, *comment.CreatedAt); found {
+			authorMetrics.CommentsResponded++
+			authorMetrics.totalResponseTime += responseTime.Sub(*comment.CreatedAt)
+		}
+	}
+
+	// Lead time to the first commit after opening the PR
+	var commitTimes []time.Time
+	for _, commit := range commits {
+		commitTimes = append(commitTimes, *commit.CreatedAt)
+	}
+	if firstCommitAt, found := getNextResponseTime(commitTimes, *data.pr.CreatedAt); found {
+		authorMetrics.firstCommitPRs++
+		authorMetrics.totalTimeToFirstCommit += firstCommitAt.Sub(*data.pr.CreatedAt)
+	}
+
+	return map[string]*AuthorMetrics{author: authorMetrics}
+}
+
+// Returns the commits the author of the pull request made, leaving out those of reviewers, co-authors and bots. None
+// are left when the author or the commit authors are unknown.
+func getAuthorCommits(commits []*gitclient.RepositoryCommit, pr *gitclient.PullRequest) []*gitclient.RepositoryCommit {
+	var result []*gitclient.RepositoryCommit
+	for _, commit := range commits {
+		if pr.UserID != nil && commit.AuthorID != nil && *commit.AuthorID == *pr.UserID {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+// Returns the earliest of the times after the given time
+func getNextResponseTime(times []time.Time, after time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+
+	for _, t := range times {
+		if t.After(after) && (!found || t.Before(next)) {
+			next = t
+			found = true
+		}
+	}
+
+	return next, found
+}
+
+// Adds the running sums and counts of the partial author metrics into the aggregated metrics.
+func reduceAuthorMetrics(metrics map[string]*AuthorMetrics, partial map[string]*AuthorMetrics) {
+	for author, authorMetrics := range partial {
+		if _, exists := metrics[author]; !exists {
+			metrics[author] = &AuthorMetrics{}
+		}
+		metrics[author].add(authorMetrics)
+	}
+}
+
+// Final calculations for averages, based on the running sums
+func finalizeAuthorMetrics(metrics map[string]*AuthorMetrics) {
+	for _, authorMetrics := range metrics {
+		if authorMetrics.CommentsResponded > 0 {
+			authorMetrics.AverageAuthorResponseTime = authorMetrics.totalResponseTime / time.Duration(authorMetrics.CommentsResponded)
+		}
+		if authorMetrics.firstCommitPRs > 0 {
+			authorMetrics.AverageTimeToFirstCommit = authorMetrics.totalTimeToFirstCommit / time.Duration(authorMetrics.firstCommitPRs)
+		}
+	}
+}
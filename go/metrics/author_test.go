@@ -0,0 +1,103 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"src/gitclient"
+	"src/metrics"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateAuthorMetrics_ResponseTime(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	reviewerCommittedAt := commentedAt.Add(30 * time.Minute)
+	repliedAt := commentedAt.Add(1 * time.Hour)
+	committedAt := commentedAt.Add(3 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), UserID: github.Int64(1)},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, UserLogin: github.String("reviewer1"), Path: github.String("a.go"), CreatedAt: &commentedAt},
+		{PullRequestReviewID: 2, UserID: 1, UserLogin: github.String("contributor1"), Path: github.String("a.go"), CreatedAt: &repliedAt},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt},
+	}, nil)
+	mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	mockClient.On("GetCommits", "owner", "repo", 1, commentedAt, []string{"a.go"}).Return([]*gitclient.RepositoryCommit{
+		{CreatedAt: &reviewerCommittedAt, AuthorID: github.Int64(11)}, {CreatedAt: &committedAt, AuthorID: github.Int64(1)},
+	}, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	results, errs := metrics.CalculateAuthorMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions, the reply comes before the author's commit and the reviewer's own commit isn't a response
+	assert.Len(t, errs, 0)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 1, results["contributor1"].PRsAuthored)
+	assert.Equal(t, 1, results["contributor1"].CommentsResponded)
+	assert.Equal(t, 1*time.Hour, results["contributor1"].AverageAuthorResponseTime)
+}
+
+func TestCalculateAuthorMetrics_TimeToFirstCommit(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	branchedAt := createdAt.Add(-2 * time.Hour)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	firstCommitAt := createdAt.Add(150 * time.Minute)
+	secondCommitAt := createdAt.Add(4 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), UserID: github.Int64(1)},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, UserLogin: github.String("reviewer1"), Path: github.String("a.go"), CreatedAt: &commentedAt},
+	}
+
+	// The commit made before opening the PR doesn't count
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt},
+	}, nil)
+	mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	mockClient.On("GetCommits", "owner", "repo", 1, commentedAt, []string{"a.go"}).Return([]*gitclient.RepositoryCommit{
+		{CreatedAt: &branchedAt, AuthorID: github.Int64(1)}, {CreatedAt: &secondCommitAt, AuthorID: github.Int64(1)}, {CreatedAt: &firstCommitAt, AuthorID: github.Int64(1)},
+	}, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	results, errs := metrics.CalculateAuthorMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 150*time.Minute, results["contributor1"].AverageTimeToFirstCommit)
+
+	// The average is kept when merging
+	merged := metrics.MergeAuthors(results, results)
+	assert.Equal(t, 150*time.Minute, merged["contributor1"].AverageTimeToFirstCommit)
+}
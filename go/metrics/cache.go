@@ -0,0 +1,210 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"src/gitclient"
+)
+
+// Cache keeps the fetched data of the pull requests of a repository between runs, so that later runs only fetch the
+// pull requests updated since. The data depends on the options, so a cache must be reused with the same options.
+type Cache struct {
+	UpdatedAt    time.Time                  // Start of the last run that fetched every updated pull request
+	PullRequests map[int]*CachedPullRequest // By pull request number
+
+	// Pull requests in the range that the last run took from the cache without fetching them. Not kept between runs.
+	Hits int `json:"-"`
+}
+
+// CachedPullRequest is the fetched data of a single pull request
+type CachedPullRequest struct {
+	Repo         string // owner/repo, empty in caches written before it was recorded
+	PullRequest  *gitclient.PullRequest
+	Reviews      []*gitclient.PullRequestReview
+	Comments     []*gitclient.PullRequestComment
+	Discussion   []*gitclient.IssueComment
+	Commits      []*gitclient.RepositoryCommit
+	LineStats    *gitclient.LineStats
+	Events       []*gitclient.TimelineEvent
+	Reactions    map[int64][]*gitclient.Reaction
+	ReviewEdits  map[int64]time.Time
+	ChangedFiles []string
+	Patches      []*gitclient.RepositoryCommitFile
+	Threads      []*gitclient.ReviewThread
+	Excluded     map[string]string
+}
+
+// Creates the cache entry of the fetched data
+func newCachedPullRequest(data *pullRequestData) *CachedPullRequest {
+	return &CachedPullRequest{
+		Repo:         data.repo,
+		PullRequest:  data.pr,
+		Reviews:      getAllReviews(data.userReviews),
+		Comments:     data.comments,
+		Discussion:   data.discussion,
+		Commits:      data.commits,
+		LineStats:    data.lineStats,
+		Events:       data.events,
+		Reactions:    data.reactions,
+		ReviewEdits:  data.reviewEdits,
+		ChangedFiles: data.changedFiles,
+		Patches:      data.patches,
+		Threads:      data.threads,
+		Excluded:     data.excluded,
+	}
+}
+
+// Restores the fetched data from the cache entry
+func (c *CachedPullRequest) data() *pullRequestData {
+	return &pullRequestData{
+		repo:         c.Repo,
+		pr:           c.PullRequest,
+		userReviews:  getUserReviews(c.Reviews),
+		comments:     c.Comments,
+		discussion:   c.Discussion,
+		commits:      c.Commits,
+		lineStats:    c.LineStats,
+		events:       c.Events,
+		reactions:    c.Reactions,
+		reviewEdits:  c.ReviewEdits,
+		changedFiles: c.ChangedFiles,
+		patches:      c.Patches,
+		threads:      c.Threads,
+		excluded:     c.Excluded,
+	}
+}
+
+// CalculateMetricsIncremental calculates the same metrics as CalculateMetrics, but only fetches the pull requests
+// updated since the cache was last updated and takes the others from the cache. The cache is updated in place, for
+// the caller to keep it for the next run. An empty cache fetches all pull requests created in the range.
+func CalculateMetricsIncremental(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options, cache *Cache) (map[string]*ContributorMetrics, []error) {
+	startedAt := time.Now()
+
+	var prs []*gitclient.PullRequest
+	var err error
+	if cache.UpdatedAt.IsZero() {
+		prs, err = client.GetPullRequests(owner, repo, dateFrom, dateTo)
+	} else {
+		prs, err = client.GetPullRequestsUpdated(owner, repo, cache.UpdatedAt, dateTo)
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+	if cache.PullRequests == nil {
+		cache.PullRequests = make(map[int]*CachedPullRequest)
+	}
+	for _, cached := range cache.PullRequests {
+		if cached.Repo == "" {
+			cached.Repo = owner + "/" + repo // Cached before the repository was recorded
+		}
+	}
+
+	// Refresh the updated pull requests
+	refreshed := make(map[int]bool)
+	complete := true
+	for _, pr := range prs {
+		if ctx.Err() != nil {
+			log.Printf("Stopping early, returning partial results: %v\n", ctx.Err())
+			complete = false
+			break
+		}
+
+		if pr.CreatedAt.Before(dateFrom) {
+			continue
+		}
+		if options.Label != "" && !hasLabel(pr, options.Label) {
+			delete(cache.PullRequests, pr.Number) // The label may have been removed since
+			continue
+		}
+		if !options.IncludeMergeQueue && isMergeQueue(pr) {
+			continue
+		}
+		if options.isExcludedPR(owner+"/"+repo, pr) {
+			continue
+		}
+
+		log.Printf("PR: %s (API rate used: %d, API rate remining %d)\n", getProgressName(pr, options.RedactTitles), client.GetApiRateUsed(), client.GetApiRateRemaining())
+
+		data, errs := fetchPullRequestData(client, owner, repo, pr, dateFrom, dateTo, options)
+		if err := findRateLimitReached(errs); err != nil {
+			log.Printf("Stopping early, returning partial results: %v\n", err)
+			complete = false
+			break
+		}
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		if data == nil {
+			delete(cache.PullRequests, pr.Number)
+			continue
+		}
+
+		cache.PullRequests[pr.Number] = newCachedPullRequest(data)
+		refreshed[pr.Number] = true
+	}
+
+	// An interrupted run leaves the cache where it was, so the next run fetches the rest of the updates
+	if complete {
+		cache.UpdatedAt = startedAt
+	}
+
+	cache.Hits = 0
+	for number, cached := range cache.PullRequests {
+		if !refreshed[number] && cached.matches(dateFrom, dateTo, options) {
+			cache.Hits++
+		}
+	}
+
+	// Recalculate from all cached pull requests in the range
+	return cache.Metrics(dateFrom, dateTo, options), nil
+}
+
+// Metrics calculates the metrics of the cached pull requests created in the range and matching the options
+func (c *Cache) Metrics(dateFrom time.Time, dateTo time.Time, options Options) map[string]*ContributorMetrics {
+	numbers := make([]int, 0, len(c.PullRequests))
+	for number := range c.PullRequests {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	var prs []*pullRequestData
+	for _, number := range numbers {
+		if c.PullRequests[number].matches(dateFrom, dateTo, options) {
+			data := c.PullRequests[number].data()
+			runPlugins(data, options)
+			recordExcluded(data, options)
+			prs = append(prs, data)
+		}
+	}
+	setSessionGaps(prs, options.SessionGapPercentile)
+
+	metrics := make(map[string]*ContributorMetrics)
+	for _, data := range prs {
+		reduceMetrics(metrics, calculatePullRequestMetrics(data, options))
+	}
+
+	finalizeMetrics(metrics)
+
+	return metrics
+}
+
+// Reports whether the pull request was created in the range and is included by the options
+func (c *CachedPullRequest) matches(dateFrom time.Time, dateTo time.Time, options Options) bool {
+	pr := c.PullRequest
+	if pr.CreatedAt.Before(dateFrom) || pr.CreatedAt.After(dateTo) {
+		return false
+	}
+	if !options.IncludeMergeQueue && isMergeQueue(pr) {
+		return false
+	}
+	if options.isExcludedPR(c.Repo, pr) {
+		return false
+	}
+	if options.hasSizeBand() && !options.inSizeBand(c.LineStats) {
+		return false
+	}
+	return options.Label == "" || hasLabel(pr, options.Label)
+}
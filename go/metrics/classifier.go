@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CommentClassifier categorizes the body of a review comment for ContributorMetrics.CommentCategories, e.g. as one of
+// the Category constants. An empty category leaves the comment out.
+type CommentClassifier func(body string) string
+
+// Categories of the comments reported by KeywordClassifier
+const (
+	CategoryBlocking = "blocking"
+	CategoryQuestion = "question"
+	CategoryNit      = "nit"
+	CategoryPraise   = "praise"
+)
+
+var (
+	nitKeywords      = regexp.MustCompile(`^(nit|nitpick|minor|optional)\b`)
+	blockingKeywords = regexp.MustCompile(`\b(must|blocker|blocking|breaks|will break|bug|incorrect|wrong|security)\b`)
+	questionKeywords = regexp.MustCompile(`\?|^(why|what|how|when|where|is|are|does|do|should|could|would)\b`)
+	praiseKeywords   = regexp.MustCompile(`\b(nice|great|lgtm|love|thanks|thank you|well done|good catch|neat|awesome)\b`)
+)
+
+// KeywordClassifier is the default CommentClassifier, telling the category by keywords, ignoring case. A comment
+// marked as a nit is one regardless of the rest, otherwise blocking keywords take precedence over questions, and
+// questions over praise. Comments without any of the keywords are left out.
+func KeywordClassifier(body string) string {
+	body = strings.ToLower(strings.TrimSpace(body))
+
+	switch {
+	case nitKeywords.MatchString(body):
+		return CategoryNit
+	case blockingKeywords.MatchString(body):
+		return CategoryBlocking
+	case questionKeywords.MatchString(body):
+		return CategoryQuestion
+	case praiseKeywords.MatchString(body):
+		return CategoryPraise
+	}
+	return ""
+}
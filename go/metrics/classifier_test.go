@@ -0,0 +1,61 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"src/gitclient"
+	"src/metrics"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywordClassifier(t *testing.T) {
+	assert.Equal(t, metrics.CategoryQuestion, metrics.KeywordClassifier("Why not reuse the existing helper?"))
+	assert.Equal(t, metrics.CategoryPraise, metrics.KeywordClassifier("Nice, this reads much better"))
+	assert.Equal(t, metrics.CategoryBlocking, metrics.KeywordClassifier("This must be closed, it leaks the connection"))
+	assert.Equal(t, metrics.CategoryNit, metrics.KeywordClassifier("nit: trailing whitespace"))
+	assert.Equal(t, metrics.CategoryNit, metrics.KeywordClassifier("Nit: is this name clearer?"))
+	assert.Equal(t, "", metrics.KeywordClassifier("Renamed in the next commit"))
+}
+
+func TestCalculateMetrics_CommentClassifier(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateCommented},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 1, UserID: 11, Body: "What happens when the list is empty?", CreatedAt: &dateTo},
+		{ID: 2, PullRequestReviewID: 1, UserID: 11, Body: "Is the lock still needed here?", CreatedAt: &dateTo},
+		{ID: 3, PullRequestReviewID: 1, UserID: 11, Body: "Great test coverage", CreatedAt: &dateTo},
+		{ID: 4, PullRequestReviewID: 1, UserID: 11, Body: "nit: typo in the name", CreatedAt: &dateTo},
+		{ID: 5, PullRequestReviewID: 1, UserID: 11, Body: "Moved it up", CreatedAt: &dateTo},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Without a classifier no comments are categorized
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	assert.Nil(t, metricsResult["reviewer1"].CommentCategories)
+
+	metricsResult, errs = metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{CommentClassifier: metrics.KeywordClassifier})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]int{metrics.CategoryQuestion: 2, metrics.CategoryPraise: 1, metrics.CategoryNit: 1}, metricsResult["reviewer1"].CommentCategories)
+
+	// The categories add up when merging
+	merged := metrics.Merge(metricsResult, metricsResult)
+	assert.Equal(t, 4, merged["reviewer1"].CommentCategories[metrics.CategoryQuestion])
+}
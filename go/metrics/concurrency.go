@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"src/gitclient"
+)
+
+// Time before every activity of a reviewer in which the distinct pull requests they touched make up their
+// ContextSwitchIndex
+const contextSwitchWindow = time.Hour
+
+// reviewWindow spans a reviewer's activity on a single pull request, from the first to the last comment or review
+type reviewWindow struct {
+	start    time.Time
+	end      time.Time
+	activity []time.Time // Times of the comments and review submissions, for the context switches between PRs
+}
+
+// Returns the span of the reviews and comments, which must not all be empty
+func newReviewWindow(reviews []*gitclient.PullRequestReview, comments []*gitclient.PullRequestComment) reviewWindow {
+	times := getSubmittedTimes(reviews)
+	for _, comment := range comments {
+		times = append(times, *comment.CreatedAt)
+	}
+
+	window := reviewWindow{start: times[0], end: times[0], activity: times}
+	for _, t := range times[1:] {
+		if t.Before(window.start) {
+			window.start = t
+		}
+		if t.After(window.end) {
+			window.end = t
+		}
+	}
+	return window
+}
+
+// Returns the most windows that overlap at any moment, and the average number of overlapping windows over the time
+// at least one of them lasts. Windows touching at one instant overlap. The average is 0 when no window has a duration.
+func concurrentReviews(windows []reviewWindow) (maxConcurrent int, average float64) {
+	type edge struct {
+		at    time.Time
+		delta int
+	}
+
+	edges := make([]edge, 0, 2*len(windows))
+	for _, window := range windows {
+		edges = append(edges, edge{window.start, 1}, edge{window.end, -1})
+	}
+
+	// Starts before ends at the same instant, so touching windows count as overlapping
+	sort.Slice(edges, func(i, j int) bool {
+		if !edges[i].at.Equal(edges[j].at) {
+			return edges[i].at.Before(edges[j].at)
+		}
+		return edges[i].delta > edges[j].delta
+	})
+
+	var weighted, busy time.Duration
+	current := 0
+	for i, e := range edges {
+		if i > 0 && current > 0 {
+			elapsed := e.at.Sub(edges[i-1].at)
+			weighted += elapsed * time.Duration(current)
+			busy += elapsed
+		}
+		current += e.delta
+		maxConcurrent = max(maxConcurrent, current)
+	}
+
+	if busy > 0 {
+		average = float64(weighted) / float64(busy)
+	}
+	return maxConcurrent, average
+}
+
+// Returns the distinct windows, i.e. pull requests, with activity in the contextSwitchWindow up to and including every
+// activity, averaged over the activities. It is 1 for a reviewer finishing with one PR before turning to another, and
+// 0 without any activity.
+func contextSwitchIndex(windows []reviewWindow) float64 {
+	type event struct {
+		at     time.Time
+		window int
+	}
+
+	var events []event
+	for i, window := range windows {
+		for _, at := range window.activity {
+			events = append(events, event{at, i})
+		}
+	}
+	if len(events) == 0 {
+		return 0
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+
+	// Slide the window over the events, counting the events of every PR in it
+	inWindow := make(map[int]int)
+	total, first := 0, 0
+	for _, e := range events {
+		inWindow[e.window]++
+		for events[first].at.Before(e.at.Add(-contextSwitchWindow)) {
+			if inWindow[events[first].window]--; inWindow[events[first].window] == 0 {
+				delete(inWindow, events[first].window)
+			}
+			first++
+		}
+		total += len(inWindow)
+	}
+
+	return float64(total) / float64(len(events))
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"math"
+)
+
+// Delta compares the current results with a baseline and returns, for every contributor whose metrics changed beyond
+// the threshold, the differences current minus baseline. The threshold is relative to the baseline value, e.g. 0.1
+// reports changes of more than 10%; any change from a zero baseline counts. Contributors missing on one side are
+// compared against empty metrics. FirstReviewDate is not a difference and keeps the current value.
+func Delta(current, baseline map[string]*ContributorMetrics, threshold float64) map[string]*ContributorMetrics {
+	result := make(map[string]*ContributorMetrics)
+
+	contributors := make(map[string]struct{})
+	for user := range current {
+		contributors[user] = struct{}{}
+	}
+	for user := range baseline {
+		contributors[user] = struct{}{}
+	}
+
+	for user := range contributors {
+		currentMetrics, baselineMetrics := current[user], baseline[user]
+		if currentMetrics == nil {
+			currentMetrics = &ContributorMetrics{}
+		}
+		if baselineMetrics == nil {
+			baselineMetrics = &ContributorMetrics{}
+		}
+
+		if changedBeyond(metricValues(currentMetrics), metricValues(baselineMetrics), threshold) {
+			result[user] = subtractMetrics(currentMetrics, baselineMetrics)
+		}
+	}
+
+	return result
+}
+
+// Returns the exported metrics as numbers, in the order of the fields
+func metricValues(m *ContributorMetrics) []float64 {
+	return []float64{
+		float64(m.PRsReviewed),
+		float64(m.TotalComments),
+		m.AverageCommentsPerReview,
+		float64(m.AverageTimeToFirstReview),
+		float64(m.AverageTimeToCompleteReview),
+		float64(m.TotalLinesReviewed),
+		m.AverageLinesReviewed,
+		m.PercentageCommentsLeadingToChanges,
+		m.ReviewsPerActiveDay,
+		float64(m.ApprovalsGiven),
+		m.ApprovalRate,
+		float64(m.InstantApprovals),
+		m.CommentDensity,
+		m.SLAComplianceRate,
+		float64(m.DistinctFilesCommented),
+		float64(m.DaysActiveInRange),
+		m.CrossTeamReviewShare,
+		float64(m.AcknowledgedComments),
+		float64(m.CoAuthoredReviews),
+		float64(m.EditedAfterSubmitReviews),
+		float64(m.NitComments),
+		m.NitpickRatio,
+		float64(m.LongestReviewStreak),
+		float64(m.MaxConcurrentReviews),
+		m.AverageConcurrentReviews,
+		m.DisagreementRate,
+		float64(m.FirstResponderCount),
+		float64(m.TotalTimeToFirstReview),
+		float64(m.TotalTimeToCompleteReview),
+		float64(m.DelegatedAway),
+		float64(m.DelegatedTo),
+		m.CommentsBeforeApproval,
+		float64(m.EstimatedDurationCount),
+		float64(m.ChangesRequestedResolved),
+		float64(m.ChangesRequestedOverridden),
+		m.ReviewCoverage,
+		float64(m.AverageRequestToMerge),
+		m.ContextSwitchIndex,
+		m.DecayedPRsReviewed,
+		m.DecayedTotalComments,
+		float64(m.DecayedAverageTimeToFirstReview),
+	}
+}
+
+// Reports whether any current value differs from its baseline value by more than the relative threshold
+func changedBeyond(current, baseline []float64, threshold float64) bool {
+	for i := range current {
+		diff := math.Abs(current[i] - baseline[i])
+		if diff == 0 {
+			continue
+		}
+		if baseline[i] == 0 || diff > threshold*math.Abs(baseline[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the exported metrics of current minus those of baseline
+func subtractMetrics(current, baseline *ContributorMetrics) *ContributorMetrics {
+	return &ContributorMetrics{
+		PRsReviewed:                        current.PRsReviewed - baseline.PRsReviewed,
+		TotalComments:                      current.TotalComments - baseline.TotalComments,
+		AverageCommentsPerReview:           current.AverageCommentsPerReview - baseline.AverageCommentsPerReview,
+		AverageTimeToFirstReview:           current.AverageTimeToFirstReview - baseline.AverageTimeToFirstReview,
+		AverageTimeToCompleteReview:        current.AverageTimeToCompleteReview - baseline.AverageTimeToCompleteReview,
+		TotalLinesReviewed:                 current.TotalLinesReviewed - baseline.TotalLinesReviewed,
+		AverageLinesReviewed:               current.AverageLinesReviewed - baseline.AverageLinesReviewed,
+		PercentageCommentsLeadingToChanges: current.PercentageCommentsLeadingToChanges - baseline.PercentageCommentsLeadingToChanges,
+		ReviewsPerActiveDay:                current.ReviewsPerActiveDay - baseline.ReviewsPerActiveDay,
+		ApprovalsGiven:                     current.ApprovalsGiven - baseline.ApprovalsGiven,
+		ApprovalRate:                       current.ApprovalRate - baseline.ApprovalRate,
+		InstantApprovals:                   current.InstantApprovals - baseline.InstantApprovals,
+		CommentDensity:                     current.CommentDensity - baseline.CommentDensity,
+		SLAComplianceRate:                  current.SLAComplianceRate - baseline.SLAComplianceRate,
+		DistinctFilesCommented:             current.DistinctFilesCommented - baseline.DistinctFilesCommented,
+		FirstReviewDate:                    current.FirstReviewDate,
+		DaysActiveInRange:                  current.DaysActiveInRange - baseline.DaysActiveInRange,
+		CrossTeamReviewShare:               current.CrossTeamReviewShare - baseline.CrossTeamReviewShare,
+		AcknowledgedComments:               current.AcknowledgedComments - baseline.AcknowledgedComments,
+		CoAuthoredReviews:                  current.CoAuthoredReviews - baseline.CoAuthoredReviews,
+		EditedAfterSubmitReviews:           current.EditedAfterSubmitReviews - baseline.EditedAfterSubmitReviews,
+		NitComments:                        current.NitComments - baseline.NitComments,
+		NitpickRatio:                       current.NitpickRatio - baseline.NitpickRatio,
+		LongestReviewStreak:                current.LongestReviewStreak - baseline.LongestReviewStreak,
+		MaxConcurrentReviews:               current.MaxConcurrentReviews - baseline.MaxConcurrentReviews,
+		AverageConcurrentReviews:           current.AverageConcurrentReviews - baseline.AverageConcurrentReviews,
+		DisagreementRate:                   current.DisagreementRate - baseline.DisagreementRate,
+		FirstResponderCount:                current.FirstResponderCount - baseline.FirstResponderCount,
+		TotalTimeToFirstReview:             current.TotalTimeToFirstReview - baseline.TotalTimeToFirstReview,
+		TotalTimeToCompleteReview:          current.TotalTimeToCompleteReview - baseline.TotalTimeToCompleteReview,
+		DelegatedAway:                      current.DelegatedAway - baseline.DelegatedAway,
+		DelegatedTo:                        current.DelegatedTo - baseline.DelegatedTo,
+		CommentsBeforeApproval:             current.CommentsBeforeApproval - baseline.CommentsBeforeApproval,
+		EstimatedDurationCount:             current.EstimatedDurationCount - baseline.EstimatedDurationCount,
+		ChangesRequestedResolved:           current.ChangesRequestedResolved - baseline.ChangesRequestedResolved,
+		ChangesRequestedOverridden:         current.ChangesRequestedOverridden - baseline.ChangesRequestedOverridden,
+		ReviewCoverage:                     current.ReviewCoverage - baseline.ReviewCoverage,
+		AverageRequestToMerge:              current.AverageRequestToMerge - baseline.AverageRequestToMerge,
+		ContextSwitchIndex:                 current.ContextSwitchIndex - baseline.ContextSwitchIndex,
+		LowConfidence:                      current.LowConfidence,
+		DecayedPRsReviewed:                 current.DecayedPRsReviewed - baseline.DecayedPRsReviewed,
+		DecayedTotalComments:               current.DecayedTotalComments - baseline.DecayedTotalComments,
+		DecayedAverageTimeToFirstReview:    current.DecayedAverageTimeToFirstReview - baseline.DecayedAverageTimeToFirstReview,
+	}
+}
@@ -0,0 +1,44 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelta_OneChangedReviewer(t *testing.T) {
+	baseline := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 10, TotalComments: 20, AverageTimeToFirstReview: 2 * time.Hour},
+		"reviewer2": {PRsReviewed: 10, TotalComments: 20},
+	}
+	current := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 6, TotalComments: 20, AverageTimeToFirstReview: 3 * time.Hour},
+		"reviewer2": {PRsReviewed: 10, TotalComments: 21},
+	}
+
+	// The 5% change of reviewer2 is below the threshold
+	deltas := metrics.Delta(current, baseline, 0.1)
+
+	assert.Len(t, deltas, 1)
+	assert.Equal(t, -4, deltas["reviewer1"].PRsReviewed)
+	assert.Equal(t, 0, deltas["reviewer1"].TotalComments)
+	assert.Equal(t, 1*time.Hour, deltas["reviewer1"].AverageTimeToFirstReview)
+}
+
+func TestDelta_NewAndMissingContributors(t *testing.T) {
+	baseline := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 2},
+	}
+	current := map[string]*metrics.ContributorMetrics{
+		"reviewer2": {PRsReviewed: 3},
+	}
+
+	deltas := metrics.Delta(current, baseline, 0.5)
+
+	assert.Len(t, deltas, 2)
+	assert.Equal(t, -2, deltas["reviewer1"].PRsReviewed)
+	assert.Equal(t, 3, deltas["reviewer2"].PRsReviewed)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"sort"
+)
+
+// ScoreWeights sets how much each normalized metric contributes to the ReviewerScore
+type ScoreWeights struct {
+	PRsReviewed    float64
+	CommentDensity float64
+	SLACompliance  float64
+}
+
+// DefaultScoreWeights favors review volume, with thoroughness and responsiveness weighted equally
+var DefaultScoreWeights = ScoreWeights{PRsReviewed: 0.5, CommentDensity: 0.25, SLACompliance: 0.25}
+
+type LeaderboardEntry struct {
+	Contributor   string
+	ReviewerScore float64
+	Metrics       *ContributorMetrics
+}
+
+// Leaderboard ranks the contributors by their composite ReviewerScore, highest first:
+//
+//	ReviewerScore = w.PRsReviewed    * PRsReviewed / max(PRsReviewed)
+//	              + w.CommentDensity * CommentDensity / max(CommentDensity)
+//	              + w.SLACompliance  * SLAComplianceRate
+//
+// PRsReviewed and CommentDensity are normalized to [0, 1] against the highest value among the contributors,
+// SLAComplianceRate already is a fraction. A metric whose maximum is zero contributes nothing. Equal scores
+// are ordered by contributor login so the ranking is stable.
+func Leaderboard(results map[string]*ContributorMetrics, weights ScoreWeights) []LeaderboardEntry {
+	maxPRsReviewed := 0
+	maxCommentDensity := 0.0
+	for _, userMetrics := range results {
+		maxPRsReviewed = max(maxPRsReviewed, userMetrics.PRsReviewed)
+		maxCommentDensity = max(maxCommentDensity, userMetrics.CommentDensity)
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(results))
+	for user, userMetrics := range results {
+		score := weights.SLACompliance * userMetrics.SLAComplianceRate
+		if maxPRsReviewed > 0 {
+			score += weights.PRsReviewed * float64(userMetrics.PRsReviewed) / float64(maxPRsReviewed)
+		}
+		if maxCommentDensity > 0 {
+			score += weights.CommentDensity * userMetrics.CommentDensity / maxCommentDensity
+		}
+
+		entries = append(entries, LeaderboardEntry{Contributor: user, ReviewerScore: score, Metrics: userMetrics})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ReviewerScore != entries[j].ReviewerScore {
+			return entries[i].ReviewerScore > entries[j].ReviewerScore
+		}
+		return entries[i].Contributor < entries[j].Contributor
+	})
+
+	return entries
+}
@@ -0,0 +1,41 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderboard_Ranking(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 10, CommentDensity: 0.01, SLAComplianceRate: 0.2},
+		"reviewer2": {PRsReviewed: 5, CommentDensity: 0.04, SLAComplianceRate: 1.0},
+	}
+
+	// Volume only
+	entries := metrics.Leaderboard(results, metrics.ScoreWeights{PRsReviewed: 1})
+	assert.Equal(t, "reviewer1", entries[0].Contributor)
+	assert.Equal(t, 1.0, entries[0].ReviewerScore)
+	assert.Equal(t, 0.5, entries[1].ReviewerScore)
+
+	// Default weights: reviewer1 = 0.5*1 + 0.25*0.25 + 0.25*0.2 = 0.6125, reviewer2 = 0.5*0.5 + 0.25*1 + 0.25*1 = 0.75
+	entries = metrics.Leaderboard(results, metrics.DefaultScoreWeights)
+	assert.Equal(t, "reviewer2", entries[0].Contributor)
+	assert.InDelta(t, 0.75, entries[0].ReviewerScore, 1e-9)
+	assert.Equal(t, "reviewer1", entries[1].Contributor)
+	assert.InDelta(t, 0.6125, entries[1].ReviewerScore, 1e-9)
+}
+
+func TestLeaderboard_TiesOrderedByLogin(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"zed":   {PRsReviewed: 3},
+		"alice": {PRsReviewed: 3},
+	}
+
+	entries := metrics.Leaderboard(results, metrics.DefaultScoreWeights)
+
+	assert.Equal(t, "alice", entries[0].Contributor)
+	assert.Equal(t, "zed", entries[1].Contributor)
+}
@@ -1,9 +1,14 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"regexp"
 	"sort"
+	"strconv"
 
 	"strings"
 	"time"
@@ -17,120 +22,1703 @@ type ContributorMetrics struct {
 	AverageCommentsPerReview           float64
 	AverageTimeToFirstReview           time.Duration
 	AverageTimeToCompleteReview        time.Duration
+	TotalTimeToFirstReview             time.Duration // Sum over every review submitted, divided by PRsReviewed for AverageTimeToFirstReview
+	TotalTimeToCompleteReview          time.Duration // Sum over every review submitted, or every PR with SessionAcrossReviews, divided by PRsReviewed for AverageTimeToCompleteReview
 	TotalLinesReviewed                 int
 	AverageLinesReviewed               float64
 	PercentageCommentsLeadingToChanges float64
+	ReviewsPerActiveDay                float64
+	ApprovalsGiven                     int
+	ApprovalRate                       float64
+	InstantApprovals                   int
+	CommentDensity                     float64
+	SLAComplianceRate                  float64
+	DistinctFilesCommented             int
+	FirstReviewDate                    time.Time
+	DaysActiveInRange                  int            // Days between the first and the last review in the range
+	CrossTeamReviewShare               float64        // Share of the PRs reviewed, among those with known teams, authored outside the reviewer's teams
+	AcknowledgedComments               int            // Comments the author reacted to, other than with -1 or confused
+	ReviewsByHour                      [24]int        // Submitted reviews by hour of the day, in the reviewer's timezone
+	CoAuthoredReviews                  int            // PRs reviewed that the reviewer also pushed commits to
+	EditedAfterSubmitReviews           int            // Reviews whose body was edited long after they were submitted
+	NitComments                        int            // Comments starting with one of the nitpick prefixes
+	NitpickRatio                       float64        // Share of the comments that are nitpicks
+	LongestReviewStreak                int            // Most consecutive calendar days with at least one submitted review
+	MaxConcurrentReviews               int            // Most PRs the reviewer was reviewing at the same time
+	AverageConcurrentReviews           float64        // PRs the reviewer was reviewing at the same time, averaged over the time spent reviewing
+	ContextSwitchIndex                 float64        // Distinct PRs the reviewer touched in the hour up to each of their comments and reviews, averaged over them
+	DisagreementRate                   float64        // Share of the PRs decided by several reviewers where their final states differed
+	FirstResponderCount                int            // PRs with several reviewers where the reviewer submitted the first review
+	DelegatedAway                      int            // PRs the reviewer was requested on but others reviewed instead
+	DelegatedTo                        int            // PRs the reviewer reviewed unrequested in place of a requested reviewer who didn't
+	CommentsBeforeApproval             float64        // Comments the reviewer left on a PR up to approving it, averaged over the PRs they approved
+	EstimatedDurationCount             int            // Reviews, or PRs with SessionAcrossReviews, timed at the minimum review duration for lack of comments to time them by
+	ChangesRequestedResolved           int            // Changes requests followed by the reviewer's approval or, with ThreadResolution, with all their threads resolved
+	ChangesRequestedOverridden         int            // Changes requests of merged PRs that weren't resolved, the PR merged over them
+	ReviewCoverage                     float64        // Share of the changed hunks of the PRs reviewed with a comment of the reviewer, with Options.ReviewCoverage
+	AverageRequestToMerge              time.Duration  // Time from requesting the reviewer to merging, averaged over the merged PRs they were requested on, with Options.RequestToMerge
+	CommentCategories                  map[string]int // Comments by the category of the CommentClassifier, nil without it
+	LowConfidence                      bool           // Set by MarkLowConfidence when too few PRs were reviewed for the averages to mean much
+
+	// Recency-weighted counterparts of PRsReviewed, TotalComments and AverageTimeToFirstReview with DecayHalfLife,
+	// every PR and review weighted by half for every half-life of its age. Zero without DecayHalfLife.
+	DecayedPRsReviewed              float64
+	DecayedTotalComments            float64
+	DecayedAverageTimeToFirstReview time.Duration
+
+	// Running sums preserved so that results can be merged before the averages are recomputed
+	commentsLeadingToChanges int
+	reviewsSubmitted         int
+	reviewsWithinSLA         int
+	reviewDays               map[string]struct{} // Distinct days (YYYY-MM-DD) with at least one submitted review
+	filesCommented           map[string]struct{} // Distinct paths of the files the reviewer commented on
+	filesReviewed            map[string]struct{} // Distinct paths of the files the reviewer commented on or, with ChangedFiles, reviewed
+	lastReviewDate           time.Time
+	teamClassifiedPRs        int // PRs reviewed where both the reviewer and the author belong to a known team
+	crossTeamPRs             int
+	reviewWindows            []reviewWindow          // Activity span and times on every PR reviewed
+	jointlyDecidedPRs        int                     // PRs the reviewer and at least one other reviewer approved or requested changes on
+	disagreedPRs             int                     // Jointly decided PRs where some reviewers approved and others requested changes
+	spreadPRs                float64                 // PRs with several reviewers, each split evenly among its reviewers
+	reviewSpread             time.Duration           // Time between the earliest and the latest first review of those PRs, split the same way
+	approvedPRs              int                     // PRs the reviewer approved at least once
+	commentsBeforeApproval   int                     // Comments on those PRs up to the first approval
+	hunksReviewed            int                     // Changed hunks of the PRs reviewed, with Options.ReviewCoverage
+	hunksCommented           int                     // Those of the hunks the reviewer commented on
+	requestToMergePRs        int                     // Merged PRs reviewed after a review request, with Options.RequestToMerge
+	totalRequestToMerge      time.Duration           // Time from the first review request to the merge of those PRs
+	weeks                    map[string]weekActivity // Reviews and their comments by ISO week of the submission in Options.Location, e.g. 2025-W02
+	decayedReviews           float64                 // Reviews submitted, weighted by recency
+	decayedTimeToFirstReview float64                 // Time to first review in nanoseconds, weighted by recency
+}
+
+// Creates empty ContributorMetrics
+func newContributorMetrics() *ContributorMetrics {
+	return &ContributorMetrics{reviewDays: make(map[string]struct{}), filesCommented: make(map[string]struct{}), filesReviewed: make(map[string]struct{})}
+}
+
+// Adds the running sums and counts of other to m. Averages must be recomputed afterwards.
+func (m *ContributorMetrics) add(other *ContributorMetrics) {
+	m.PRsReviewed += other.PRsReviewed
+	m.TotalComments += other.TotalComments
+	m.TotalLinesReviewed += other.TotalLinesReviewed
+	m.TotalTimeToFirstReview += other.TotalTimeToFirstReview
+	m.TotalTimeToCompleteReview += other.TotalTimeToCompleteReview
+	m.commentsLeadingToChanges += other.commentsLeadingToChanges
+	m.reviewsSubmitted += other.reviewsSubmitted
+	m.reviewsWithinSLA += other.reviewsWithinSLA
+	m.ApprovalsGiven += other.ApprovalsGiven
+	m.InstantApprovals += other.InstantApprovals
+	m.AcknowledgedComments += other.AcknowledgedComments
+	m.CoAuthoredReviews += other.CoAuthoredReviews
+	m.EditedAfterSubmitReviews += other.EditedAfterSubmitReviews
+	m.NitComments += other.NitComments
+	m.FirstResponderCount += other.FirstResponderCount
+	m.EstimatedDurationCount += other.EstimatedDurationCount
+	m.ChangesRequestedResolved += other.ChangesRequestedResolved
+	m.ChangesRequestedOverridden += other.ChangesRequestedOverridden
+	m.DelegatedAway += other.DelegatedAway
+	m.DelegatedTo += other.DelegatedTo
+	for category, count := range other.CommentCategories {
+		m.addCommentCategory(category, count)
+	}
+	for week, activity := range other.weeks {
+		m.addWeekActivity(week, activity)
+	}
+	for hour := range m.ReviewsByHour {
+		m.ReviewsByHour[hour] += other.ReviewsByHour[hour]
+	}
+	m.teamClassifiedPRs += other.teamClassifiedPRs
+	m.reviewWindows = append(m.reviewWindows, other.reviewWindows...)
+	m.jointlyDecidedPRs += other.jointlyDecidedPRs
+	m.disagreedPRs += other.disagreedPRs
+	m.spreadPRs += other.spreadPRs
+	m.reviewSpread += other.reviewSpread
+	m.DecayedPRsReviewed += other.DecayedPRsReviewed
+	m.DecayedTotalComments += other.DecayedTotalComments
+	m.approvedPRs += other.approvedPRs
+	m.commentsBeforeApproval += other.commentsBeforeApproval
+	m.hunksReviewed += other.hunksReviewed
+	m.hunksCommented += other.hunksCommented
+	m.requestToMergePRs += other.requestToMergePRs
+	m.totalRequestToMerge += other.totalRequestToMerge
+	m.decayedReviews += other.decayedReviews
+	m.decayedTimeToFirstReview += other.decayedTimeToFirstReview
+	m.crossTeamPRs += other.crossTeamPRs
+
+	if !other.FirstReviewDate.IsZero() && (m.FirstReviewDate.IsZero() || other.FirstReviewDate.Before(m.FirstReviewDate)) {
+		m.FirstReviewDate = other.FirstReviewDate
+	}
+	if other.lastReviewDate.After(m.lastReviewDate) {
+		m.lastReviewDate = other.lastReviewDate
+	}
+
+	for day := range other.reviewDays {
+		m.reviewDays[day] = struct{}{}
+	}
+	for path := range other.filesCommented {
+		m.filesCommented[path] = struct{}{}
+	}
+	for path := range other.filesReviewed {
+		m.filesReviewed[path] = struct{}{}
+	}
+}
+
+// Adds count comments of the category to CommentCategories
+func (m *ContributorMetrics) addCommentCategory(category string, count int) {
+	if m.CommentCategories == nil {
+		m.CommentCategories = make(map[string]int)
+	}
+	m.CommentCategories[category] += count
+}
+
+// Reviews and comments of a single week, for WeeklyLoad
+type weekActivity struct {
+	reviews  int
+	comments int
+}
+
+// Adds the activity to the week of weeks
+func (m *ContributorMetrics) addWeekActivity(week string, activity weekActivity) {
+	if m.weeks == nil {
+		m.weeks = make(map[string]weekActivity)
+	}
+	total := m.weeks[week]
+	total.reviews += activity.reviews
+	total.comments += activity.comments
+	m.weeks[week] = total
+}
+
+// Options controls which pull requests are included and how the metrics are calculated.
+type Options struct {
+	Label                string        // Only include pull requests carrying this label. Empty includes all pull requests.
+	IncludeMergeQueue    bool          // Include the ephemeral pull requests of merge queues, left out by default
+	SessionAcrossReviews bool          // Compute review sessions over all of a reviewer's comments on a PR instead of per review
+	SLA                  time.Duration // Time to first review considered compliant. Zero disables the SLA compliance rate.
+
+	// Only include pull requests changing at least MinChangedLines and at most MaxChangedLines lines, added and deleted.
+	// Their size is fetched for every pull request then, one extra request each. Zero leaves the bound out.
+	MinChangedLines int
+	MaxChangedLines int
+
+	// Pull requests left out, e.g. migrations and bulk reformats that skew the metrics
+	ExcludePRs []PullRequestRef
+
+	// Approvals without comments submitted faster than this after the review was requested count as InstantApprovals. Zero disables the detection.
+	InstantApprovalThreshold time.Duration
+
+	// Derive the session gap of every reviewer from their cadence over the range, as this percentile (e.g. 90) of the
+	// intervals between their comments and review submissions on every PR. The data of all PRs is held until the gaps
+	// are known. Zero keeps the fixed DefaultSessionGap.
+	SessionGapPercentile float64
+
+	// Time spent per comment when all comments of a review were posted at submission, which leaves nothing to measure
+	// sessions from. Zero keeps measuring the sessions, falling back to the minimum review duration.
+	PerCommentDuration time.Duration
+
+	// Fetch the reactions to the comments to count the ones acknowledged by the author
+	Acknowledgements bool
+
+	// Fetch the comments of the general discussion of every pull request, one extra request each, and time the
+	// reviews by them together with the review comments. A review is timed by the discussion comments of the reviewer
+	// since their previous review, or by all of them with SessionAcrossReviews.
+	IssueComments bool
+
+	// Start the time to first review when a draft was marked ready for review instead of at creation
+	FromReadyForReview bool
+
+	// Fetch the timeline of every pull request to count the review requests others completed, as DelegatedAway of the
+	// requested reviewers and DelegatedTo of the reviewers who took over
+	Delegation bool
+
+	// Fetch the timeline of every merged pull request to measure AverageRequestToMerge, the time from requesting a
+	// reviewer to merging. Pull requests found through the search API have no merge time and are left out.
+	RequestToMerge bool
+
+	// Fetch the commits of every pull request to count CoAuthoredReviews, and optionally leave the co-authored pull
+	// requests out of the other metrics of the reviewer. Excluding implies detecting.
+	DetectCoAuthored  bool
+	ExcludeCoAuthored bool
+
+	// Fetch the commits of every pull request and treat the co-authors named in their Co-authored-by trailers as
+	// authors, leaving their reviews out like self-reviews
+	CoAuthorTrailers bool
+
+	// Reviews edited later than this after submission count as EditedAfterSubmitReviews. The edit times come from the
+	// GraphQL API, one extra request per pull request. Zero disables the detection.
+	EditedAfterSubmit time.Duration
+
+	// Comments whose body starts with one of these prefixes, ignoring case, count as NitComments. Empty disables the
+	// classification.
+	NitPrefixes []string
+
+	// Categorizes every review comment, e.g. with KeywordClassifier, counting the comments of every reviewer by
+	// category. Nil disables the categorization.
+	CommentClassifier CommentClassifier
+
+	// Basis of including pull requests and reviews in the date range, one of the Scope constants. Empty means
+	// ScopeCreated.
+	Scope string
+
+	// Leave the Saturdays and Sundays, in the reviewer's timezone, out of the time to first review
+	SkipWeekends bool
+
+	// Fetch the changed files of every pull request, so that FileReviewerCoverage counts the reviewers of a pull
+	// request as engaged with all of its files rather than only the ones they commented on
+	ChangedFiles bool
+
+	// Fetch the patches of the files changed by every pull request, one extra request each, for the ReviewCoverage of
+	// the changed hunks. They also list the changed files for ChangedFiles then.
+	ReviewCoverage bool
+
+	// Fetch the review threads of every pull request from the GraphQL API, one extra request per pull request, and
+	// count a changes request with all of its threads resolved as ChangesRequestedResolved even without an approval
+	ThreadResolution bool
+
+	// Leave out the reviews of GitHub Apps and Actions, by the account type rather than the [bot] suffix of the login
+	ExcludeApps bool
+
+	// Only include the reviews of reviewers with one of these relationships to the repository, e.g.
+	// gitclient.AuthorAssociationMember to leave out external contributors. Empty includes all reviewers.
+	AuthorAssociations []string
+
+	// Log the pull requests by number instead of title, keeping confidential titles out of CI logs
+	RedactTitles bool
+
+	// Timezone of the reviews by hour, UTC when nil, and the timezones of individual reviewers overriding it
+	Location      *time.Location
+	UserLocations map[string]*time.Location
+
+	// Weight the decayed metrics down by half for every half-life of age of a review, measured at DecayFrom, e.g. the
+	// end of the range, or at the time of the calculation when it is zero. Zero half-life disables the decayed metrics.
+	DecayHalfLife time.Duration
+	DecayFrom     time.Time
+
+	// Custom metric computations invoked for every pull request, accumulating into PluginResults, which must be
+	// non-nil when there are plugins. Calculations running at the same time need a PluginResults each.
+	Plugins       []MetricPlugin
+	PluginResults map[string]float64
+
+	// Filled, when non-nil, with the reviewers whose reviews ExcludeApps or AuthorAssociations left out, by login, and
+	// the reason, one of the Exclusion constants. Calculations running at the same time need an Excluded each.
+	Excluded map[string]string
+
+	// Members of every team by team name, used to tell in-team from cross-team reviews. Nil disables the classification.
+	Teams map[string][]string
+}
+
+// Bases of including pull requests and reviews in the date range
+const (
+	ScopeCreated    = "created"     // Pull requests created in the range, with all of their reviews
+	ScopeReviewDate = "review-date" // Reviews submitted in the range, whenever their pull request was created
+)
+
+// Reports whether the options rely on the timeline even for pull requests without comments.
+func (o Options) needsTimeline() bool {
+	return o.InstantApprovalThreshold > 0 || o.FromReadyForReview || o.Delegation
+}
+
+// Reports whether the options limit the pull requests by their size.
+func (o Options) hasSizeBand() bool {
+	return o.MinChangedLines > 0 || o.MaxChangedLines > 0
+}
+
+// PullRequestRef identifies a pull request among those of several repositories
+type PullRequestRef struct {
+	Repo   string // owner/repo
+	Number int
+}
+
+func (r PullRequestRef) String() string {
+	return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+}
+
+// Reports whether the pull request of the repository (owner/repo) is one of ExcludePRs.
+func (o Options) isExcludedPR(repo string, pr *gitclient.PullRequest) bool {
+	for _, ref := range o.ExcludePRs {
+		if strings.EqualFold(ref.Repo, repo) && ref.Number == pr.Number {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether the lines the pull request changed are within MinChangedLines and MaxChangedLines.
+func (o Options) inSizeBand(lineStats *gitclient.LineStats) bool {
+	changedLines := lineStats.Additions + lineStats.Deletions
+	return changedLines >= o.MinChangedLines && (o.MaxChangedLines == 0 || changedLines <= o.MaxChangedLines)
+}
+
+// Reports whether the options rely on the commits even for pull requests without comments.
+func (o Options) needsCommits() bool {
+	return o.DetectCoAuthored || o.ExcludeCoAuthored || o.CoAuthorTrailers
+}
+
+// Holds everything fetched for a single pull request.
+type pullRequestData struct {
+	repo         string // owner/repo
+	pr           *gitclient.PullRequest
+	userReviews  map[string][]*gitclient.PullRequestReview
+	comments     []*gitclient.PullRequestComment
+	discussion   []*gitclient.IssueComment // Comments of the general discussion, only with IssueComments
+	commits      []*gitclient.RepositoryCommit
+	lineStats    *gitclient.LineStats
+	events       []*gitclient.TimelineEvent
+	reactions    map[int64][]*gitclient.Reaction   // By comment ID, only for comments with reactions
+	reviewEdits  map[int64]time.Time               // Last edit by review ID, only for edited reviews
+	changedFiles []string                          // Paths of the changed files, only with ChangedFiles
+	patches      []*gitclient.RepositoryCommitFile // Changed files with their patches, only with ReviewCoverage
+	threads      []*gitclient.ReviewThread         // Review threads, only with ThreadResolution
+	excluded     map[string]string                 // Reason the reviews of a reviewer were left out, by login
+	sessionGaps  map[string]time.Duration          // Session gap of every reviewer over the range, only with SessionGapPercentile
+}
+
+// Prefixes the path of a file with its repository, so that the same paths in different repositories stay apart
+// once the metrics of several repositories are merged
+func (data *pullRequestData) repoPath(file string) string {
+	if data.repo == "" {
+		return file
+	}
+	return data.repo + "/" + file
+}
+
+func CalculateMetrics(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options) (map[string]*ContributorMetrics, []error) {
+	metrics := make(map[string]*ContributorMetrics)
+
+	errs := forEachPullRequest(ctx, client, owner, repo, dateFrom, dateTo, options, func(data *pullRequestData) {
+		reduceMetrics(metrics, calculatePullRequestMetrics(data, options))
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	finalizeMetrics(metrics)
+
+	return metrics, nil
+}
+
+// CalculateMetricsByLabel calculates the metrics separately for every label found on the pull requests.
+// A pull request carrying several labels contributes to each of them.
+func CalculateMetricsByLabel(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options) (map[string]map[string]*ContributorMetrics, []error) {
+	results := make(map[string]map[string]*ContributorMetrics)
+
+	errs := forEachPullRequest(ctx, client, owner, repo, dateFrom, dateTo, options, func(data *pullRequestData) {
+		partial := calculatePullRequestMetrics(data, options)
+
+		for _, label := range data.pr.Labels {
+			if _, exists := results[label]; !exists {
+				results[label] = make(map[string]*ContributorMetrics)
+			}
+			reduceMetrics(results[label], partial)
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	for _, metrics := range results {
+		finalizeMetrics(metrics)
+	}
+
+	return results, nil
+}
+
+// CalculateMetricsByMergedState calculates the metrics separately for merged, closed-unmerged and open pull requests,
+// keyed by the gitclient.MergedState constants. This separates the review effort spent on abandoned work.
+func CalculateMetricsByMergedState(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options) (map[string]map[string]*ContributorMetrics, []error) {
+	results := make(map[string]map[string]*ContributorMetrics)
+
+	errs := forEachPullRequest(ctx, client, owner, repo, dateFrom, dateTo, options, func(data *pullRequestData) {
+		state := data.pr.MergedState
+		if _, exists := results[state]; !exists {
+			results[state] = make(map[string]*ContributorMetrics)
+		}
+		reduceMetrics(results[state], calculatePullRequestMetrics(data, options))
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	for _, metrics := range results {
+		finalizeMetrics(metrics)
+	}
+
+	return results, nil
+}
+
+// CalculateMetricsByMonth calculates the metrics per contributor and calendar month (YYYY-MM). Each review is assigned
+// to the month it was submitted in, so a pull request reviewed in two months counts towards both.
+func CalculateMetricsByMonth(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options) (map[string]map[string]*ContributorMetrics, []error) {
+	byMonth := make(map[string]map[string]*ContributorMetrics)
+
+	errs := forEachPullRequest(ctx, client, owner, repo, dateFrom, dateTo, options, func(data *pullRequestData) {
+		for month, monthData := range splitByMonth(data) {
+			if _, exists := byMonth[month]; !exists {
+				byMonth[month] = make(map[string]*ContributorMetrics)
+			}
+			reduceMetrics(byMonth[month], calculatePullRequestMetrics(monthData, options))
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	// Regroup by contributor first
+	results := make(map[string]map[string]*ContributorMetrics)
+	for month, metrics := range byMonth {
+		finalizeMetrics(metrics)
+
+		for user, userMetrics := range metrics {
+			if _, exists := results[user]; !exists {
+				results[user] = make(map[string]*ContributorMetrics)
+			}
+			results[user][month] = userMetrics
+		}
+	}
+
+	return results, nil
+}
+
+// Splits the reviews of the pull request by the month they were submitted in. The rest of the data is shared.
+func splitByMonth(data *pullRequestData) map[string]*pullRequestData {
+	result := make(map[string]*pullRequestData)
+
+	for user, reviews := range data.userReviews {
+		for _, review := range reviews {
+			month := review.SubmittedAt.Format("2006-01")
+
+			if _, exists := result[month]; !exists {
+				monthData := *data
+				monthData.userReviews = make(map[string][]*gitclient.PullRequestReview)
+				result[month] = &monthData
+			}
+			result[month].userReviews[user] = append(result[month].userReviews[user], review)
+		}
+	}
+
+	return result
+}
+
+// AddInactive adds all-zero metrics for the logins missing from results, so that the people expected to review
+// still show up when they did no reviews in the range.
+func AddInactive(results map[string]*ContributorMetrics, logins []string) {
+	for _, login := range logins {
+		if _, exists := results[login]; !exists {
+			results[login] = newContributorMetrics()
+		}
+	}
+}
+
+// MarkLowConfidence sets LowConfidence on the metrics of the contributors who reviewed fewer than minSampleSize pull
+// requests. Merging results clears it, so it's marked on the results as output.
+func MarkLowConfidence(results map[string]*ContributorMetrics, minSampleSize int) {
+	for _, userMetrics := range results {
+		userMetrics.LowConfidence = userMetrics.PRsReviewed < minSampleSize
+	}
+}
+
+// Merge combines several results (e.g. from different repositories) into one. The averages are recomputed
+// from the underlying sums and counts rather than averaging the averages. The inputs are not modified.
+func Merge(results ...map[string]*ContributorMetrics) map[string]*ContributorMetrics {
+	merged := make(map[string]*ContributorMetrics)
+
+	for _, metrics := range results {
+		reduceMetrics(merged, metrics)
+	}
+
+	finalizeMetrics(merged)
+
+	return merged
+}
+
+// Fetches the pull requests in the date range and calls process with the data of each pull request matching the options.
+// When the context is cancelled or the rate limit is reached, it stops between pull requests so the ones already
+// processed are kept.
+func forEachPullRequest(ctx context.Context, client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time, options Options, process func(data *pullRequestData)) []error {
+	getPullRequests := client.GetPullRequests
+	if options.Scope == ScopeReviewDate {
+		getPullRequests = client.GetPullRequestsUpdated
+	}
+
+	prs, err := getPullRequests(owner, repo, dateFrom, dateTo)
+	if err != nil {
+		return []error{err}
+	}
+
+	var buffered []*pullRequestData
+	for _, pr := range prs {
+		if ctx.Err() != nil {
+			log.Printf("Stopping early, returning partial results: %v\n", ctx.Err())
+			break
+		}
+
+		if options.Label != "" && !hasLabel(pr, options.Label) {
+			continue
+		}
+		if !options.IncludeMergeQueue && isMergeQueue(pr) {
+			continue
+		}
+		if options.isExcludedPR(owner+"/"+repo, pr) {
+			continue
+		}
+
+		log.Printf("PR: %s (API rate used: %d, API rate remining %d)\n", getProgressName(pr, options.RedactTitles), client.GetApiRateUsed(), client.GetApiRateRemaining())
+
+		data, errs := fetchPullRequestData(client, owner, repo, pr, dateFrom, dateTo, options)
+		if err := findRateLimitReached(errs); err != nil {
+			log.Printf("Stopping early, returning partial results: %v\n", err)
+			break
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+		if data == nil {
+			continue // No reviews in the range
+		}
+
+		runPlugins(data, options)
+		recordExcluded(data, options)
+		if options.SessionGapPercentile > 0 {
+			buffered = append(buffered, data) // Processed once the cadence over the whole range is known
+			continue
+		}
+		process(data)
+	}
+
+	setSessionGaps(buffered, options.SessionGapPercentile)
+	for _, data := range buffered {
+		process(data)
+	}
+
+	return nil
+}
+
+// Returns the name of the pull request in the progress log
+func getProgressName(pr *gitclient.PullRequest, redactTitles bool) string {
+	if redactTitles || pr.Title == nil {
+		return fmt.Sprintf("#%d", pr.Number)
+	}
+	return *pr.Title
+}
+
+// MetricsError is an error of fetching the data of a pull request, telling which pull request and which call failed
+type MetricsError struct {
+	Repo      string // owner/repo
+	PRNumber  int
+	Operation string // GitClient method that failed, e.g. GetReviews
+	Err       error
+}
+
+func (e *MetricsError) Error() string {
+	return fmt.Sprintf("%s of %s#%d: %v", e.Operation, e.Repo, e.PRNumber, e.Err)
+}
+
+func (e *MetricsError) Unwrap() error {
+	return e.Err
+}
+
+// Wraps the error of the operation on the pull request into a MetricsError
+func newMetricsError(owner, repo string, prNumber int, operation string, err error) error {
+	return &MetricsError{Repo: owner + "/" + repo, PRNumber: prNumber, Operation: operation, Err: err}
+}
+
+// Wraps every error of the operation on the pull request into a MetricsError
+func newMetricsErrors(owner, repo string, prNumber int, operation string, errs []error) []error {
+	result := make([]error, len(errs))
+	for i, err := range errs {
+		result[i] = newMetricsError(owner, repo, prNumber, operation, err)
+	}
+	return result
+}
+
+// Returns the error of reaching the rate limit among the errors, or nil. It ends the run without failing it.
+func findRateLimitReached(errs []error) error {
+	for _, err := range errs {
+		if errors.Is(err, gitclient.ErrRateLimitReached) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fetches reviews, comments, commits and, when needed, the timeline of the pull request. With ScopeReviewDate only the
+// reviews submitted in the range are kept, and nil is returned when there are none.
+func fetchPullRequestData(client gitclient.GitClient, owner, repo string, pr *gitclient.PullRequest, dateFrom, dateTo time.Time, options Options) (*pullRequestData, []error) {
+	// Fetch reviews
+	reviewsRaw, err := client.GetReviews(owner, repo, pr.Number)
+	if err != nil {
+		return nil, []error{newMetricsError(owner, repo, pr.Number, "GetReviews", err)}
+	}
+	if options.Scope == ScopeReviewDate {
+		reviewsRaw = getReviewsSubmittedIn(reviewsRaw, dateFrom, dateTo)
+		if len(reviewsRaw) == 0 {
+			return nil, nil
+		}
+	}
+	excluded := getExcludedReviewers(reviewsRaw, options)
+	if options.ExcludeApps {
+		reviewsRaw = getReviewsByUsers(reviewsRaw)
+	}
+	if len(options.AuthorAssociations) > 0 {
+		reviewsRaw = getReviewsByAssociation(reviewsRaw, options.AuthorAssociations)
+	}
+
+	// Fetch the size of the PR up front when it decides whether the PR is included
+	var lineStats *gitclient.LineStats
+
+	if options.hasSizeBand() {
+		lineStats, err = client.GetLineStats(owner, repo, pr.Number)
+		if err != nil {
+			return nil, []error{newMetricsError(owner, repo, pr.Number, "GetLineStats", err)}
+		}
+		if !options.inSizeBand(lineStats) {
+			return nil, nil
+		}
+	}
+
+	// Fetch comments
+	comments, err := client.GetComments(owner, repo, pr.Number)
+	if err != nil {
+		return nil, []error{newMetricsError(owner, repo, pr.Number, "GetComments", err)}
+	}
+
+	// Fetch commits for the PR to track changes after comments
+	var commits []*gitclient.RepositoryCommit
+	var errs []error
+
+	if len(comments) > 0 {
+		commits, errs = client.GetCommits(owner, repo, pr.Number, *comments[0].CreatedAt, getCommentedPaths(comments))
+		if len(errs) > 0 {
+			return nil, newMetricsErrors(owner, repo, pr.Number, "GetCommits", errs)
+		}
+	} else if options.needsCommits() {
+		// Only the authors are needed, not the changed files
+		commits, errs = client.GetCommits(owner, repo, pr.Number, *pr.CreatedAt, nil)
+		if len(errs) > 0 {
+			return nil, newMetricsErrors(owner, repo, pr.Number, "GetCommits", errs)
+		}
+	}
+
+	// Fetch the timeline to find force-pushes that shift the comment positions, and review requests
+	var events []*gitclient.TimelineEvent
+
+	if len(comments) > 0 || options.needsTimeline() || options.RequestToMerge && pr.MergedAt != nil {
+		events, err = client.GetTimelineEvents(owner, repo, pr.Number)
+		if err != nil {
+			return nil, []error{newMetricsError(owner, repo, pr.Number, "GetTimelineEvents", err)}
+		}
+		markPositionUnstableComments(comments, events)
+	}
+
+	// Fetch the size of the PR, unless it's known already or nobody but the author reviewed it
+	userReviews := getUserReviews(reviewsRaw)
+
+	if lineStats == nil {
+		lineStats = &gitclient.LineStats{}
+		if hasReviewsFromOthers(userReviews, *pr.UserLogin) {
+			lineStats, err = client.GetLineStats(owner, repo, pr.Number)
+			if err != nil {
+				return nil, []error{newMetricsError(owner, repo, pr.Number, "GetLineStats", err)}
+			}
+		}
+	}
+
+	// Fetch the reactions of the comments by others that have any
+	var reactions map[int64][]*gitclient.Reaction
+
+	if options.Acknowledgements {
+		reactions = make(map[int64][]*gitclient.Reaction)
+		for _, comment := range comments {
+			if comment.ReactionCount == 0 || (comment.UserLogin != nil && *comment.UserLogin == *pr.UserLogin) {
+				continue
+			}
+
+			reactions[comment.ID], err = client.GetCommentReactions(owner, repo, comment.ID)
+			if err != nil {
+				return nil, []error{newMetricsError(owner, repo, pr.Number, "GetCommentReactions", err)}
+			}
+		}
+	}
+
+	// Fetch when the reviews were last edited
+	var reviewEdits map[int64]time.Time
+
+	if options.EditedAfterSubmit > 0 {
+		reviewEdits, err = client.GetReviewEdits(owner, repo, pr.Number)
+		if err != nil {
+			return nil, []error{newMetricsError(owner, repo, pr.Number, "GetReviewEdits", err)}
+		}
+	}
+
+	// Fetch the patches of the changed files, for the review coverage of the hunks
+	var patches []*gitclient.RepositoryCommitFile
+
+	if options.ReviewCoverage && hasReviewsFromOthers(userReviews, *pr.UserLogin) {
+		patches, err = client.GetChangedFilePatches(owner, repo, pr.Number)
+		if err != nil {
+			return nil, []error{newMetricsError(owner, repo, pr.Number, "GetChangedFilePatches", err)}
+		}
+	}
+
+	// Fetch the changed files, for the reviewer coverage of the files, unless the patches list them already
+	var changedFiles []string
+
+	if options.ChangedFiles && hasReviewsFromOthers(userReviews, *pr.UserLogin) {
+		if options.ReviewCoverage {
+			changedFiles = make([]string, 0, len(patches))
+			for _, file := range patches {
+				if file.Filename != nil {
+					changedFiles = append(changedFiles, *file.Filename)
+				}
+			}
+		} else {
+			changedFiles, err = client.GetChangedFiles(owner, repo, pr.Number)
+			if err != nil {
+				return nil, []error{newMetricsError(owner, repo, pr.Number, "GetChangedFiles", err)}
+			}
+		}
+	}
+
+	// Fetch the discussion comments, for timing the reviews by them too
+	var discussion []*gitclient.IssueComment
+
+	if options.IssueComments && hasReviewsFromOthers(userReviews, *pr.UserLogin) {
+		discussion, err = client.GetIssueComments(owner, repo, pr.Number)
+		if err != nil {
+			return nil, []error{newMetricsError(owner, repo, pr.Number, "GetIssueComments", err)}
+		}
+	}
+
+	// Fetch the review threads, for the changes requests resolved without an approval
+	var threads []*gitclient.ReviewThread
+
+	if options.ThreadResolution && len(comments) > 0 {
+		threads, err = client.GetReviewThreads(owner, repo, pr.Number)
+		if err != nil {
+			return nil, []error{newMetricsError(owner, repo, pr.Number, "GetReviewThreads", err)}
+		}
+	}
+
+	return &pullRequestData{repo: owner + "/" + repo, pr: pr, userReviews: userReviews, comments: comments, discussion: discussion, commits: commits, lineStats: lineStats, events: events, reactions: reactions, reviewEdits: reviewEdits, changedFiles: changedFiles, patches: patches, threads: threads, excluded: excluded}, nil
+}
+
+// Calculates the partial metrics of a single pull request, independent of any other pull request. They only hold
+// the running sums and counts; the averages are computed once all partial metrics are reduced.
+func calculatePullRequestMetrics(data *pullRequestData, options Options) map[string]*ContributorMetrics {
+	metrics := make(map[string]*ContributorMetrics)
+	pr := data.pr
+	reviewComments := getReviewComments(data.comments)
+	userTeams := getUserTeams(options.Teams)
+	defaultLocation := options.Location
+	if defaultLocation == nil {
+		defaultLocation = time.UTC
+	}
+
+	// Whether the reviewers reached the same verdict
+	verdicts := getFinalVerdicts(data.userReviews, *pr.UserLogin)
+	disagreed := hasMixedVerdicts(verdicts)
+
+	// Co-authors of the commits, whose reviews are self-reviews
+	var trailerCoAuthors coAuthors
+	if options.CoAuthorTrailers {
+		trailerCoAuthors = getTrailerCoAuthors(data.commits)
+	}
+
+	// Who picked the PR up before the other reviewers, and how long the last one took to follow
+	firstReviews := getFirstReviewTimes(data.userReviews, *pr.UserLogin)
+	for user := range firstReviews {
+		if trailerCoAuthors.includes(user, data.userReviews[user][0].UserID) {
+			delete(firstReviews, user)
+		}
+	}
+	firstResponders := getFirstResponders(firstReviews)
+	spread := getReviewSpread(firstReviews)
+
+	// Requested reviewers who left the review to others
+	var requested map[string]bool
+	delegated := false
+	if options.Delegation {
+		requested = getRequestedReviewers(data.events, *pr.UserLogin)
+		if hasReviewsFromOthers(data.userReviews, *pr.UserLogin) {
+			for user := range requested {
+				if _, reviewed := data.userReviews[user]; !reviewed {
+					userMetrics := newContributorMetrics()
+					userMetrics.DelegatedAway++
+					metrics[user] = userMetrics
+					delegated = true
+				}
+			}
+		}
+	}
+
+	// The time to first review counts from creation, or from leaving the draft state
+	reviewClockStart := *pr.CreatedAt
+	if options.FromReadyForReview {
+		if readyAt := getReadyForReviewAt(data.events); readyAt != nil {
+			reviewClockStart = *readyAt
+		}
+	}
+
+	// The age of the reviews for the decayed metrics
+	decayFrom := options.DecayFrom
+	if decayFrom.IsZero() {
+		decayFrom = time.Now()
+	}
+
+	// Iterate through the reviews to calculate metrics
+	for user, reviews := range data.userReviews {
+
+		if user != *pr.UserLogin && !trailerCoAuthors.includes(user, reviews[0].UserID) {
+			// Increase number od PRs reviewed
+			userMetrics := newContributorMetrics()
+			metrics[user] = userMetrics
+
+			// Share of the PR in the team's review spread
+			if len(firstReviews) > 1 {
+				userMetrics.spreadPRs += 1 / float64(len(firstReviews))
+				userMetrics.reviewSpread += spread / time.Duration(len(firstReviews))
+			}
+
+			// Reviewers who also committed to the PR were partly authors
+			if options.needsCommits() && isCommitAuthor(data.commits, reviews[0].UserID) {
+				userMetrics.CoAuthoredReviews++
+				if options.ExcludeCoAuthored {
+					continue
+				}
+			}
+
+			userMetrics.PRsReviewed++
+			if options.DecayHalfLife > 0 {
+				userMetrics.DecayedPRsReviewed += decayWeight(firstReviews[user], decayFrom, options.DecayHalfLife)
+			}
+			if firstResponders[user] {
+				userMetrics.FirstResponderCount++
+			}
+			if delegated && !requested[user] {
+				userMetrics.DelegatedTo++
+			}
+
+			// Time the PR waited to merge from requesting the reviewer
+			if options.RequestToMerge && pr.MergedAt != nil {
+				if requestedAt := getFirstReviewRequestedAt(data.events, user); requestedAt != nil {
+					userMetrics.requestToMergePRs++
+					userMetrics.totalRequestToMerge += max(pr.MergedAt.Sub(*requestedAt), 0)
+				}
+			}
+
+			location := defaultLocation
+			if userLocation, exists := options.UserLocations[user]; exists {
+				location = userLocation
+			}
+
+			// Comments the reviewer's time is measured by
+			timedComments := getTimedComments(data, reviews[0].UserID)
+
+			// Longest pause between comments that still continues the review session
+			sessionGap := DefaultSessionGap
+			if gap, exists := data.sessionGaps[user]; exists {
+				sessionGap = gap
+			}
+
+			// Agreement with the other reviewers who decided on the PR
+			if _, decided := verdicts[user]; decided && len(verdicts) > 1 {
+				userMetrics.jointlyDecidedPRs++
+				if disagreed {
+					userMetrics.disagreedPRs++
+				}
+			}
+
+			// Lines of Code Reviewed
+			userMetrics.TotalLinesReviewed += data.lineStats.Additions + data.lineStats.Deletions
+
+			// Files the reviewer is considered to have looked at
+			for _, path := range data.changedFiles {
+				userMetrics.filesReviewed[data.repoPath(path)] = struct{}{}
+			}
+
+			// Changed hunks the reviewer commented on
+			if options.ReviewCoverage {
+				hunks, commented := hunkCoverage(data.patches, getUserComments(data.comments, reviews[0].UserID))
+				userMetrics.hunksReviewed += hunks
+				userMetrics.hunksCommented += commented
+			}
+
+			// Reviews of PRs of other teams, when the teams of both are known
+			if reviewerTeams, authorTeams := userTeams[user], userTeams[*pr.UserLogin]; len(reviewerTeams) > 0 && len(authorTeams) > 0 {
+				userMetrics.teamClassifiedPRs++
+				if !sharesTeam(reviewerTeams, authorTeams) {
+					userMetrics.crossTeamPRs++
+				}
+			}
+
+			for _, review := range reviews {
+				// Track the distinct days the reviewer was active, in their timezone
+				userMetrics.reviewDays[review.SubmittedAt.In(location).Format("2006-01-02")] = struct{}{}
+
+				// Time of the day of the review, local to the reviewer
+				userMetrics.ReviewsByHour[review.SubmittedAt.In(location).Hour()]++
+
+				// Track the span the reviewer was active in
+				reviewDate := truncateToDay(*review.SubmittedAt)
+				if userMetrics.FirstReviewDate.IsZero() || reviewDate.Before(userMetrics.FirstReviewDate) {
+					userMetrics.FirstReviewDate = reviewDate
+				}
+				if reviewDate.After(userMetrics.lastReviewDate) {
+					userMetrics.lastReviewDate = reviewDate
+				}
+
+				// Reviews rewritten long after submission may hide what the author actually received
+				if editedAt, ok := data.reviewEdits[review.ID]; ok && editedAt.Sub(*review.SubmittedAt) > options.EditedAfterSubmit {
+					userMetrics.EditedAfterSubmitReviews++
+				}
+
+				// Approvals out of all submitted reviews
+				userMetrics.reviewsSubmitted++
+				if review.State == gitclient.ReviewStateApproved {
+					userMetrics.ApprovalsGiven++
+
+					// Approvals submitted right after the request without a comment are likely rubber stamps
+					if options.InstantApprovalThreshold > 0 && len(reviewComments[review.ID][review.UserID]) == 0 {
+						requestedAt := getReviewRequestedAt(data.events, user, *review.SubmittedAt)
+						if requestedAt != nil && review.SubmittedAt.Sub(*requestedAt) < options.InstantApprovalThreshold {
+							userMetrics.InstantApprovals++
+						}
+					}
+				}
+
+				// Average Time to First Review
+				firstReviewTime := review.SubmittedAt
+				timeToFirstReview := max(firstReviewTime.Sub(reviewClockStart), 0) // Reviews of the draft count as immediate
+				if options.SkipWeekends {
+					timeToFirstReview = max(timeToFirstReview-weekendOverlap(reviewClockStart, *firstReviewTime, location), 0)
+				}
+				userMetrics.TotalTimeToFirstReview += timeToFirstReview
+
+				// First-response SLA compliance
+				if options.SLA > 0 && timeToFirstReview <= options.SLA {
+					userMetrics.reviewsWithinSLA++
+				}
+
+				// Average time for review
+				if !options.SessionAcrossReviews {
+					timed := reviewComments[review.ID][review.UserID]
+					if discussion := getDiscussionBefore(data.discussion, reviews, review); len(discussion) > 0 {
+						timed = append(append([]*gitclient.PullRequestComment{}, timed...), discussion...)
+					}
+					reviewLength := estimateReviewLength(timed, *review.SubmittedAt, options.PerCommentDuration, sessionGap)
+					userMetrics.TotalTimeToCompleteReview += reviewLength
+					if len(timed) == 0 {
+						userMetrics.EstimatedDurationCount++ // Nothing to time the review by but its submission
+					}
+				}
+
+				// Comments per Review
+				userMetrics.TotalComments += len(reviewComments[review.ID][review.UserID])
+
+				// Load of the week, in the timezone of the team so that the weeks of all reviewers line up
+				userMetrics.addWeekActivity(isoWeek(review.SubmittedAt.In(defaultLocation)), weekActivity{reviews: 1, comments: len(reviewComments[review.ID][review.UserID])})
+
+				// Recent reviews weigh more in the decayed metrics
+				if options.DecayHalfLife > 0 {
+					weight := decayWeight(*review.SubmittedAt, decayFrom, options.DecayHalfLife)
+					userMetrics.decayedReviews += weight
+					userMetrics.decayedTimeToFirstReview += weight * float64(timeToFirstReview)
+					userMetrics.DecayedTotalComments += weight * float64(len(reviewComments[review.ID][review.UserID]))
+				}
+
+				// Breadth of the review, by the files commented on
+				for _, comment := range reviewComments[review.ID][review.UserID] {
+					if comment.Path != nil {
+						userMetrics.filesCommented[data.repoPath(*comment.Path)] = struct{}{}
+						userMetrics.filesReviewed[data.repoPath(*comment.Path)] = struct{}{}
+					}
+				}
+
+				// Trivial comments, marked by the reviewer with a prefix
+				for _, comment := range reviewComments[review.ID][review.UserID] {
+					if isNitpick(comment.Body, options.NitPrefixes) {
+						userMetrics.NitComments++
+					}
+				}
+
+				// Comments by their category, e.g. questions or praise
+				if options.CommentClassifier != nil {
+					for _, comment := range reviewComments[review.ID][review.UserID] {
+						if category := options.CommentClassifier(comment.Body); category != "" {
+							userMetrics.addCommentCategory(category, 1)
+						}
+					}
+				}
+
+				// Comments acknowledged by the author with a reaction
+				for _, comment := range reviewComments[review.ID][review.UserID] {
+					if isAcknowledgedBy(data.reactions[comment.ID], *pr.UserLogin) {
+						userMetrics.AcknowledgedComments++
+					}
+				}
+
+				// Comments Leading to Changes
+				for _, comment := range reviewComments[review.ID][review.UserID] {
+					for _, commit := range data.commits {
+						// Only consider commits made after the comment
+						if commit.CreatedAt.After(*comment.CreatedAt) {
+							if isCommentAddressedByCommit(comment, commit) {
+								userMetrics.commentsLeadingToChanges++
+								break
+							}
+						}
+					}
+				}
+			}
+
+			// Average time for review, all review rounds treated as one series of sessions
+			if options.SessionAcrossReviews {
+				sessionLength := CalculateTotalSessionLength(timedComments, getSubmittedTimes(reviews), sessionGap)
+				userMetrics.TotalTimeToCompleteReview += sessionLength
+				if len(timedComments) == 0 {
+					userMetrics.EstimatedDurationCount++
+				}
+			}
+
+			// Comments it took the reviewer to be satisfied
+			if approvedAt := getFirstApprovalAt(reviews); approvedAt != nil {
+				userMetrics.approvedPRs++
+				for _, comment := range getUserComments(data.comments, reviews[0].UserID) {
+					if !comment.CreatedAt.After(*approvedAt) {
+						userMetrics.commentsBeforeApproval++
+					}
+				}
+			}
+
+			// What became of the changes the reviewer requested
+			resolved, overridden := getChangesRequestOutcomes(reviews, data.threads, data.pr.MergedState)
+			userMetrics.ChangesRequestedResolved += resolved
+			userMetrics.ChangesRequestedOverridden += overridden
+
+			// Span of the activity on the PR, to find the PRs reviewed at the same time
+			userMetrics.reviewWindows = append(userMetrics.reviewWindows, newReviewWindow(reviews, getUserComments(data.comments, reviews[0].UserID)))
+		}
+	}
+
+	return metrics
+}
+
+// Adds the running sums and counts of the partial metrics into the aggregated metrics.
+func reduceMetrics(metrics map[string]*ContributorMetrics, partial map[string]*ContributorMetrics) {
+	for user, userMetrics := range partial {
+		if _, exists := metrics[user]; !exists {
+			metrics[user] = newContributorMetrics()
+		}
+		metrics[user].add(userMetrics)
+	}
+}
+
+// Returns the time of the latest review request for the user made no later than the given time, or nil if there is none.
+func getReviewRequestedAt(events []*gitclient.TimelineEvent, user string, before time.Time) *time.Time {
+	var requestedAt *time.Time
+
+	for _, event := range events {
+		if event.Event != gitclient.TimelineEventReviewRequested || event.CreatedAt == nil || event.ReviewerLogin == nil || *event.ReviewerLogin != user {
+			continue
+		}
+		if event.CreatedAt.After(before) {
+			continue
+		}
+		if requestedAt == nil || event.CreatedAt.After(*requestedAt) {
+			requestedAt = event.CreatedAt
+		}
+	}
+
+	return requestedAt
+}
+
+// Returns when the review of the user was first requested, or nil if it never was
+func getFirstReviewRequestedAt(events []*gitclient.TimelineEvent, user string) *time.Time {
+	var requestedAt *time.Time
+
+	for _, event := range events {
+		if event.Event != gitclient.TimelineEventReviewRequested || event.CreatedAt == nil || event.ReviewerLogin == nil || *event.ReviewerLogin != user {
+			continue
+		}
+		if requestedAt == nil || event.CreatedAt.Before(*requestedAt) {
+			requestedAt = event.CreatedAt
+		}
+	}
+
+	return requestedAt
+}
+
+// Returns the weight of what happened at the time, halving with every half-life it lies before the reference
+func decayWeight(at, reference time.Time, halfLife time.Duration) float64 {
+	age := max(reference.Sub(at), 0)
+	return math.Pow(0.5, float64(age)/float64(halfLife))
+}
+
+// Returns when the first of the reviews approved the pull request, or nil if none did
+func getFirstApprovalAt(reviews []*gitclient.PullRequestReview) *time.Time {
+	var approvedAt *time.Time
+	for _, review := range reviews {
+		if review.State == gitclient.ReviewStateApproved && (approvedAt == nil || review.SubmittedAt.Before(*approvedAt)) {
+			approvedAt = review.SubmittedAt
+		}
+	}
+	return approvedAt
+}
+
+// Counts the changes requests of the reviews of a single reviewer that were resolved, by a later approval of the
+// reviewer or by resolving all the threads the request started, and those overridden by merging the pull request
+// otherwise. The requests of open and closed-unmerged pull requests that weren't resolved count as neither.
+func getChangesRequestOutcomes(reviews []*gitclient.PullRequestReview, threads []*gitclient.ReviewThread, mergedState string) (resolved int, overridden int) {
+	approvedAt := make([]*time.Time, 0, len(reviews))
+	for _, review := range reviews {
+		if review.State == gitclient.ReviewStateApproved {
+			approvedAt = append(approvedAt, review.SubmittedAt)
+		}
+	}
+
+	for _, review := range reviews {
+		if review.State != gitclient.ReviewStateChangesRequested {
+			continue
+		}
+
+		approvedLater := false
+		for _, at := range approvedAt {
+			if at.After(*review.SubmittedAt) {
+				approvedLater = true
+			}
+		}
+
+		if approvedLater || allThreadsResolved(threads, review.ID) {
+			resolved++
+		} else if mergedState == gitclient.MergedStateMerged {
+			overridden++
+		}
+	}
+
+	return resolved, overridden
+}
+
+// Reports whether the review started any threads and all of them were resolved
+func allThreadsResolved(threads []*gitclient.ReviewThread, reviewID int64) bool {
+	started := false
+	for _, thread := range threads {
+		if thread.ReviewID != reviewID {
+			continue
+		}
+		if !thread.Resolved {
+			return false
+		}
+		started = true
+	}
+	return started
+}
+
+// Returns the ISO week of the time, e.g. 2025-W02
+func isoWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// Returns midnight of the day of the time, in its location
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Returns how much of the time between start and end falls on Saturdays and Sundays in the location
+func weekendOverlap(start, end time.Time, location *time.Location) time.Duration {
+	var overlap time.Duration
+	for day := truncateToDay(start.In(location)); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			continue
+		}
+		from := day
+		if start.After(from) {
+			from = start
+		}
+		to := day.AddDate(0, 0, 1)
+		if end.Before(to) {
+			to = end
+		}
+		overlap += max(to.Sub(from), 0)
+	}
+	return overlap
 }
 
-func CalculateMetrics(client gitclient.GitClient, owner, repo string, dateFrom time.Time, dateTo time.Time) (map[string]*ContributorMetrics, []error) {
-	metrics := make(map[string]*ContributorMetrics)
+// Inverts the members by team into the teams by user
+func getUserTeams(teams map[string][]string) map[string]map[string]struct{} {
+	result := make(map[string]map[string]struct{})
+	for team, members := range teams {
+		for _, member := range members {
+			if _, exists := result[member]; !exists {
+				result[member] = make(map[string]struct{})
+			}
+			result[member][team] = struct{}{}
+		}
+	}
+	return result
+}
 
-	prs, err := client.GetPullRequests(owner, repo, dateFrom, dateTo)
-	if err != nil {
-		return nil, []error{err}
+// Reports whether the two sets of teams have a team in common
+func sharesTeam(a, b map[string]struct{}) bool {
+	for team := range a {
+		if _, exists := b[team]; exists {
+			return true
+		}
 	}
+	return false
+}
 
-	for _, pr := range prs {
-		log.Printf("PR: %s (API rate used: %d, API rate remining %d)\n", *pr.Title, client.GetApiRateUsed(), client.GetApiRateRemaining())
+// Returns the time the pull request was first marked ready for review, or nil if it was never a draft.
+func getReadyForReviewAt(events []*gitclient.TimelineEvent) *time.Time {
+	var readyAt *time.Time
 
-		// Fetch reviews
-		reviewsRaw, err := client.GetReviews(owner, repo, pr.Number)
-		if err != nil {
-			return nil, []error{err}
+	for _, event := range events {
+		if event.Event != gitclient.TimelineEventReadyForReview || event.CreatedAt == nil {
+			continue
 		}
-		userReviews := getUserReviews(reviewsRaw)
+		if readyAt == nil || event.CreatedAt.Before(*readyAt) {
+			readyAt = event.CreatedAt
+		}
+	}
 
-		// Fetch comments
-		comments, err := client.GetComments(owner, repo, pr.Number)
-		if err != nil {
-			return nil, []error{err}
+	return readyAt
+}
+
+// Reports whether the user reacted to the comment in a way that acknowledges it
+func isAcknowledgedBy(reactions []*gitclient.Reaction, user string) bool {
+	for _, reaction := range reactions {
+		if reaction.UserLogin == nil || *reaction.UserLogin != user {
+			continue
+		}
+		if reaction.Content != gitclient.ReactionMinusOne && reaction.Content != gitclient.ReactionConfused {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether the user authored any of the commits
+func isCommitAuthor(commits []*gitclient.RepositoryCommit, userID int64) bool {
+	for _, commit := range commits {
+		if commit.AuthorID != nil && *commit.AuthorID == userID {
+			return true
 		}
-		reviewComments := getReviewComments(comments)
+	}
+	return false
+}
+
+// Matches a Co-authored-by trailer, capturing the name and the email
+var coAuthorTrailer = regexp.MustCompile(`(?im)^co-authored-by:[ \t]*(.*?)[ \t]*<([^>]*)>[ \t]*$`)
+
+// Matches the noreply email of a GitHub account, capturing the optional user ID and the login
+var noreplyEmail = regexp.MustCompile(`(?i)^(?:(\d+)\+)?([^@+]+)@users\.noreply\.github\.com$`)
 
-		// Fetch commits for the PR to track changes after comments
-		var commits []*gitclient.RepositoryCommit
-		var errs []error
+// Users named in Co-authored-by trailers, by login in lowercase and, from noreply emails, by user ID
+type coAuthors struct {
+	logins map[string]bool
+	ids    map[int64]bool
+}
+
+// Reports whether the user is among the co-authors. GitHub logins are case-insensitive.
+func (c coAuthors) includes(login string, userID int64) bool {
+	return c.logins[strings.ToLower(login)] || c.ids[userID]
+}
 
-		if len(comments) > 0 {
-			commits, errs = client.GetCommits(owner, repo, pr.Number, *comments[0].CreatedAt, true)
-			if len(errs) > 0 {
-				return nil, errs
+// Returns the co-authors named in the Co-authored-by trailers of the commits. The trailers only carry a name and an
+// email, so a co-author is recognized by a noreply email of their account, or by a name equal to their login.
+func getTrailerCoAuthors(commits []*gitclient.RepositoryCommit) coAuthors {
+	result := coAuthors{logins: make(map[string]bool), ids: make(map[int64]bool)}
+	for _, commit := range commits {
+		for _, match := range coAuthorTrailer.FindAllStringSubmatch(commit.Message, -1) {
+			if match[1] != "" {
+				result.logins[strings.ToLower(match[1])] = true
+			}
+			if email := noreplyEmail.FindStringSubmatch(match[2]); email != nil {
+				result.logins[strings.ToLower(email[2])] = true
+				if id, err := strconv.ParseInt(email[1], 10, 64); err == nil {
+					result.ids[id] = true
+				}
 			}
 		}
+	}
+	return result
+}
 
-		// Iterate through the reviews to calculate metrics
-		for user, reviews := range userReviews {
+// Reports whether the comment body starts with one of the prefixes, ignoring case and leading whitespace
+func isNitpick(body string, prefixes []string) bool {
+	body = strings.ToLower(strings.TrimSpace(body))
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(body, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
 
-			if user != *pr.UserLogin {
-				if _, exists := metrics[user]; !exists {
-					metrics[user] = &ContributorMetrics{}
-				}
+// Returns the reviews submitted between dateFrom and dateTo, inclusive
+func getReviewsSubmittedIn(reviews []*gitclient.PullRequestReview, dateFrom, dateTo time.Time) []*gitclient.PullRequestReview {
+	result := make([]*gitclient.PullRequestReview, 0, len(reviews))
+	for _, review := range reviews {
+		if review != nil && !review.SubmittedAt.Before(dateFrom) && !review.SubmittedAt.After(dateTo) {
+			result = append(result, review)
+		}
+	}
+	return result
+}
 
-				// Increase number od PRs reviewed
-				userMetrics := metrics[user]
-				userMetrics.PRsReviewed++
+// Reasons the reviews of a reviewer are left out, for Options.Excluded
+const (
+	ExclusionApp            = "app"              // A GitHub App or Action, with ExcludeApps
+	ExclusionAssociation    = "association"      // Without one of the AuthorAssociations
+	ExclusionBelowMinSample = "below-min-sample" // Reviewed fewer PRs than the minimum sample size, see MarkLowConfidence
+)
 
-				// Lines of Code Reviewed
-				// if pr.ChangedFiles != nil {
-				// 	userMetrics.TotalLinesReviewed += *pr.ChangedFiles
-				// }
+// Returns the reviewers whose reviews ExcludeApps or AuthorAssociations leave out, by login, and the reason. Nil when
+// no reviewer is left out.
+func getExcludedReviewers(reviews []*gitclient.PullRequestReview, options Options) map[string]string {
+	wanted := make(map[string]bool, len(options.AuthorAssociations))
+	for _, association := range options.AuthorAssociations {
+		wanted[association] = true
+	}
 
-				for _, review := range reviews {
-					// Average Time to First Review
-					firstReviewTime := review.SubmittedAt
-					timeToFirstReview := firstReviewTime.Sub(*pr.CreatedAt)
-					userMetrics.AverageTimeToFirstReview += timeToFirstReview
+	var result map[string]string
+	for _, review := range reviews {
+		if review == nil || review.UserLogin == nil {
+			continue
+		}
 
-					// Average time for review
-					userMetrics.AverageTimeToCompleteReview += CalculateTotalCommentPeriodLength(reviewComments[review.ID][review.UserID], *review.SubmittedAt)
+		var reason string
+		switch {
+		case options.ExcludeApps && review.UserType == gitclient.UserTypeBot:
+			reason = ExclusionApp
+		case len(wanted) > 0 && !wanted[review.AuthorAssociation]:
+			reason = ExclusionAssociation
+		default:
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[*review.UserLogin] = reason
+	}
+	return result
+}
 
-					// Comments per Review
-					userMetrics.TotalComments += len(reviewComments[review.ID][review.UserID])
+// Adds the reviewers left out of the pull request to Options.Excluded, when it is set
+func recordExcluded(data *pullRequestData, options Options) {
+	if options.Excluded == nil {
+		return
+	}
+	for login, reason := range data.excluded {
+		options.Excluded[login] = reason
+	}
+}
 
-					// Comments Leading to Changes
-					commentsLeadingToChanges := 0
+// Returns the reviews submitted by user accounts, leaving out those of GitHub Apps and Actions
+func getReviewsByUsers(reviews []*gitclient.PullRequestReview) []*gitclient.PullRequestReview {
+	result := make([]*gitclient.PullRequestReview, 0, len(reviews))
+	for _, review := range reviews {
+		if review != nil && review.UserType != gitclient.UserTypeBot {
+			result = append(result, review)
+		}
+	}
+	return result
+}
 
-					for _, comment := range comments {
-						for _, commit := range commits {
-							// Only consider commits made after the comment
-							if commit.CreatedAt.After(*comment.CreatedAt) {
-								if isCommentAddressedByCommit(comment, commit) {
-									commentsLeadingToChanges++
-									break
-								}
-							}
-						}
-					}
+// Returns the reviews submitted by reviewers with one of the relationships to the repository
+func getReviewsByAssociation(reviews []*gitclient.PullRequestReview, associations []string) []*gitclient.PullRequestReview {
+	wanted := make(map[string]bool, len(associations))
+	for _, association := range associations {
+		wanted[association] = true
+	}
 
-					userMetrics.PercentageCommentsLeadingToChanges = float64(commentsLeadingToChanges)
-				}
-			}
+	result := make([]*gitclient.PullRequestReview, 0, len(reviews))
+	for _, review := range reviews {
+		if review != nil && wanted[review.AuthorAssociation] {
+			result = append(result, review)
+		}
+	}
+	return result
+}
+
+// Returns the distinct paths of the files commented on, in ascending order
+func getCommentedPaths(comments []*gitclient.PullRequestComment) []string {
+	seen := make(map[string]struct{})
+	paths := make([]string, 0)
+	for _, comment := range comments {
+		if comment.Path == nil {
+			continue
+		}
+		if _, ok := seen[*comment.Path]; !ok {
+			seen[*comment.Path] = struct{}{}
+			paths = append(paths, *comment.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Returns the comments left by the user
+func getUserComments(comments []*gitclient.PullRequestComment, userID int64) []*gitclient.PullRequestComment {
+	result := make([]*gitclient.PullRequestComment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.UserID == userID {
+			result = append(result, comment)
+		}
+	}
+	return result
+}
+
+// Returns the discussion comment as a comment without a review, for timing it together with the review comments
+func newTimedComment(comment *gitclient.IssueComment) *gitclient.PullRequestComment {
+	return &gitclient.PullRequestComment{ID: comment.ID, UserID: comment.UserID, UserLogin: comment.UserLogin, Body: comment.Body, CreatedAt: comment.CreatedAt}
+}
+
+// Returns the discussion comments the author of the reviews posted after their review preceding the review, up to
+// its submission, for timing the review by them
+func getDiscussionBefore(discussion []*gitclient.IssueComment, reviews []*gitclient.PullRequestReview, review *gitclient.PullRequestReview) []*gitclient.PullRequestComment {
+	var previous *time.Time
+	for _, other := range reviews {
+		if other.SubmittedAt.Before(*review.SubmittedAt) && (previous == nil || other.SubmittedAt.After(*previous)) {
+			previous = other.SubmittedAt
+		}
+	}
+
+	var result []*gitclient.PullRequestComment
+	for _, comment := range discussion {
+		if comment.UserID != review.UserID || comment.CreatedAt.After(*review.SubmittedAt) || previous != nil && !comment.CreatedAt.After(*previous) {
+			continue
 		}
+		result = append(result, newTimedComment(comment))
+	}
+	return result
+}
+
+// Returns the submission times of the reviews
+func getSubmittedTimes(reviews []*gitclient.PullRequestReview) []time.Time {
+	result := make([]time.Time, len(reviews))
+	for i, review := range reviews {
+		result[i] = *review.SubmittedAt
 	}
+	return result
+}
 
-	// Final calculations for averages
+// Final calculations for averages, based on the running sums
+func finalizeMetrics(metrics map[string]*ContributorMetrics) {
 	for _, userMetrics := range metrics {
 		if userMetrics.PRsReviewed > 0 {
 			userMetrics.AverageCommentsPerReview = float64(userMetrics.TotalComments) / float64(userMetrics.PRsReviewed)
-			userMetrics.AverageTimeToFirstReview /= time.Duration(userMetrics.PRsReviewed)
-			userMetrics.AverageTimeToCompleteReview /= time.Duration(userMetrics.PRsReviewed)
+			userMetrics.AverageTimeToFirstReview = userMetrics.TotalTimeToFirstReview / time.Duration(userMetrics.PRsReviewed)
+			userMetrics.AverageTimeToCompleteReview = userMetrics.TotalTimeToCompleteReview / time.Duration(userMetrics.PRsReviewed)
 			userMetrics.AverageLinesReviewed = float64(userMetrics.TotalLinesReviewed) / float64(userMetrics.PRsReviewed)
 		}
+		userMetrics.DistinctFilesCommented = len(userMetrics.filesCommented)
+		if !userMetrics.FirstReviewDate.IsZero() {
+			userMetrics.DaysActiveInRange = int(userMetrics.lastReviewDate.Sub(userMetrics.FirstReviewDate).Hours() / 24)
+		}
+		if len(userMetrics.reviewDays) > 0 {
+			userMetrics.ReviewsPerActiveDay = float64(userMetrics.PRsReviewed) / float64(len(userMetrics.reviewDays))
+		}
+		userMetrics.LongestReviewStreak = longestStreak(userMetrics.reviewDays)
+		userMetrics.MaxConcurrentReviews, userMetrics.AverageConcurrentReviews = concurrentReviews(userMetrics.reviewWindows)
+		userMetrics.ContextSwitchIndex = contextSwitchIndex(userMetrics.reviewWindows)
+		if userMetrics.TotalLinesReviewed > 0 {
+			userMetrics.CommentDensity = float64(userMetrics.TotalComments) / float64(userMetrics.TotalLinesReviewed)
+		}
+		if userMetrics.reviewsSubmitted > 0 {
+			userMetrics.ApprovalRate = float64(userMetrics.ApprovalsGiven) / float64(userMetrics.reviewsSubmitted)
+			userMetrics.SLAComplianceRate = float64(userMetrics.reviewsWithinSLA) / float64(userMetrics.reviewsSubmitted)
+		}
+		if userMetrics.hunksReviewed > 0 {
+			userMetrics.ReviewCoverage = float64(userMetrics.hunksCommented) / float64(userMetrics.hunksReviewed)
+		}
+		if userMetrics.requestToMergePRs > 0 {
+			userMetrics.AverageRequestToMerge = userMetrics.totalRequestToMerge / time.Duration(userMetrics.requestToMergePRs)
+		}
+		if userMetrics.approvedPRs > 0 {
+			userMetrics.CommentsBeforeApproval = float64(userMetrics.commentsBeforeApproval) / float64(userMetrics.approvedPRs)
+		}
+		if userMetrics.decayedReviews > 0 {
+			userMetrics.DecayedAverageTimeToFirstReview = time.Duration(userMetrics.decayedTimeToFirstReview / userMetrics.decayedReviews)
+		}
+		if userMetrics.jointlyDecidedPRs > 0 {
+			userMetrics.DisagreementRate = float64(userMetrics.disagreedPRs) / float64(userMetrics.jointlyDecidedPRs)
+		}
+		if userMetrics.teamClassifiedPRs > 0 {
+			userMetrics.CrossTeamReviewShare = float64(userMetrics.crossTeamPRs) / float64(userMetrics.teamClassifiedPRs)
+		}
 		if userMetrics.TotalComments > 0 {
-			userMetrics.PercentageCommentsLeadingToChanges /= (float64(userMetrics.PercentageCommentsLeadingToChanges) / float64(userMetrics.TotalComments)) * 100
+			userMetrics.PercentageCommentsLeadingToChanges = float64(userMetrics.commentsLeadingToChanges) / float64(userMetrics.TotalComments) * 100
+			userMetrics.NitpickRatio = float64(userMetrics.NitComments) / float64(userMetrics.TotalComments)
 		}
 	}
+}
 
-	return metrics, nil
+// Returns the most consecutive calendar days among the days (YYYY-MM-DD)
+func longestStreak(days map[string]struct{}) int {
+	sorted := make([]string, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Strings(sorted)
+
+	longest, current := 0, 0
+	var previous time.Time
+	for _, day := range sorted {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		if current > 0 && date.Equal(previous.AddDate(0, 0, 1)) {
+			current++
+		} else {
+			current = 1
+		}
+		longest = max(longest, current)
+		previous = date
+	}
+	return longest
+}
+
+// Returns the last approval or change request of every reviewer other than the author. Reviewers who only commented
+// are left out.
+func getFinalVerdicts(userReviews map[string][]*gitclient.PullRequestReview, author string) map[string]string {
+	verdicts := make(map[string]string)
+	for user, reviews := range userReviews {
+		if user == author {
+			continue
+		}
+		for _, review := range reviews {
+			if review.State == gitclient.ReviewStateApproved || review.State == gitclient.ReviewStateChangesRequested {
+				verdicts[user] = review.State
+			}
+		}
+	}
+	return verdicts
+}
+
+// Returns the users whose review of the pull request was requested, other than the author
+func getRequestedReviewers(events []*gitclient.TimelineEvent, author string) map[string]bool {
+	requested := make(map[string]bool)
+	for _, event := range events {
+		if event.Event == gitclient.TimelineEventReviewRequested && event.ReviewerLogin != nil && *event.ReviewerLogin != author {
+			requested[*event.ReviewerLogin] = true
+		}
+	}
+	return requested
+}
+
+// Returns the time of the first review of every reviewer other than the author
+func getFirstReviewTimes(userReviews map[string][]*gitclient.PullRequestReview, author string) map[string]time.Time {
+	firstReviews := make(map[string]time.Time)
+	for user, reviews := range userReviews {
+		if user == author {
+			continue
+		}
+		for _, review := range reviews {
+			if first, exists := firstReviews[user]; !exists || review.SubmittedAt.Before(first) {
+				firstReviews[user] = *review.SubmittedAt
+			}
+		}
+	}
+	return firstReviews
+}
+
+// Returns the reviewers who submitted the first review of the pull request, only when at least two of them reviewed
+// it. Reviewers submitting at the same moment are all first.
+func getFirstResponders(firstReviews map[string]time.Time) map[string]bool {
+	responders := make(map[string]bool)
+	if len(firstReviews) < 2 {
+		return responders
+	}
+
+	var earliest time.Time
+	for _, submittedAt := range firstReviews {
+		if earliest.IsZero() || submittedAt.Before(earliest) {
+			earliest = submittedAt
+		}
+	}
+	for user, submittedAt := range firstReviews {
+		if submittedAt.Equal(earliest) {
+			responders[user] = true
+		}
+	}
+	return responders
+}
+
+// Returns the time between the earliest and the latest first review of the reviewers, zero for fewer than two
+func getReviewSpread(firstReviews map[string]time.Time) time.Duration {
+	var earliest, latest time.Time
+	for _, submittedAt := range firstReviews {
+		if earliest.IsZero() || submittedAt.Before(earliest) {
+			earliest = submittedAt
+		}
+		if submittedAt.After(latest) {
+			latest = submittedAt
+		}
+	}
+	return latest.Sub(earliest)
+}
+
+// Reports whether some reviewers approved while others requested changes
+func hasMixedVerdicts(verdicts map[string]string) bool {
+	var first string
+	for _, verdict := range verdicts {
+		if first == "" {
+			first = verdict
+		} else if verdict != first {
+			return true
+		}
+	}
+	return false
+}
+
+// Reports whether anyone other than the author reviewed the pull request.
+func hasReviewsFromOthers(userReviews map[string][]*gitclient.PullRequestReview, author string) bool {
+	for user := range userReviews {
+		if user != author {
+			return true
+		}
+	}
+	return false
+}
+
+// Branches and author of the pull requests created by GitHub merge queues
+const (
+	mergeQueueBranchPrefix = "gh-readonly-queue/"
+	mergeQueueLogin        = "github-merge-queue[bot]"
+)
+
+// Reports whether a merge queue created the pull request, by its branch or author.
+func isMergeQueue(pr *gitclient.PullRequest) bool {
+	if pr.HeadRef != nil && strings.HasPrefix(*pr.HeadRef, mergeQueueBranchPrefix) {
+		return true
+	}
+	return pr.UserLogin != nil && *pr.UserLogin == mergeQueueLogin
+}
+
+// Reports whether the pull request carries the label.
+func hasLabel(pr *gitclient.PullRequest, label string) bool {
+	for _, prLabel := range pr.Labels {
+		if prLabel == label {
+			return true
+		}
+	}
+	return false
 }
 
-// Groups pull request comments by their associated review ID and user ID.
+// Groups pull request comments by their associated review ID and user ID. Comments without a review, which GitHub
+// reports with a null review ID, are left out rather than grouped under a review with ID zero.
 func getReviewComments(comments []*gitclient.PullRequestComment) map[int64](map[int64][]*gitclient.PullRequestComment) {
 	// Initialize the top-level map
 	result := make(map[int64](map[int64][]*gitclient.PullRequestComment))
 
 	// Iterate through all comments provided in the input slice.
 	for _, comment := range comments {
+		if comment.PullRequestReviewID == 0 {
+			continue
+		}
+
 		// Extract the review ID and user ID from the comment.
 		reviewID := comment.PullRequestReviewID
 		userID := comment.UserID
@@ -178,23 +1766,129 @@ func getUserReviews(reviews []*gitclient.PullRequestReview) map[string][]*gitcli
 	return result
 }
 
-// CalculateTotalPeriodLength computes the total duration of all periods based on a 30-minute threshold.
-func CalculateTotalCommentPeriodLength(reviewComments []*gitclient.PullRequestComment, reviewSubmittedAt time.Time) time.Duration {
-	minDuration := 3 * time.Minute // If there are no comments, use this value. There is no easy way to identify when user started the review, so use this value if time less than minDuration.
+// Longest pause between comments that still continues a review session, unless Options.SessionGapPercentile adapts it
+const DefaultSessionGap = 30 * time.Minute
+
+// Fewest intervals between a reviewer's comments to derive their session gap from; fewer keep DefaultSessionGap
+const minCadenceIntervals = 3
+
+// If there are no comments, use this value. There is no easy way to identify when user started the review, so use this value if time less than minReviewDuration.
+// Review times equal to it are estimates, counted in EstimatedDurationCount.
+const minReviewDuration = 3 * time.Minute
+
+// Estimates the review duration from the sessions of the comments. Comments batched at the submission of the review
+// carry no timing information, so with perComment set their count times perComment is used instead.
+func estimateReviewLength(reviewComments []*gitclient.PullRequestComment, reviewSubmittedAt time.Time, perComment time.Duration, sessionGap time.Duration) time.Duration {
+	if perComment > 0 && isBatchedAtSubmission(reviewComments, reviewSubmittedAt) {
+		return max(time.Duration(len(reviewComments))*perComment, minReviewDuration)
+	}
+
+	return CalculateTotalCommentPeriodLength(reviewComments, reviewSubmittedAt, sessionGap)
+}
+
+// Returns the comments of the user the review time is measured by, with the discussion comments timed like review
+// comments
+func getTimedComments(data *pullRequestData, userID int64) []*gitclient.PullRequestComment {
+	timedComments := getUserComments(data.comments, userID)
+	for _, comment := range data.discussion {
+		if comment.UserID == userID {
+			timedComments = append(timedComments, newTimedComment(comment))
+		}
+	}
+	return timedComments
+}
+
+// Sets the session gap of every reviewer on the pull requests, derived from their cadence on all of them together.
+// Nothing is set when percentile is zero, keeping DefaultSessionGap.
+func setSessionGaps(prs []*pullRequestData, percentile float64) {
+	if percentile <= 0 {
+		return
+	}
+
+	intervals := make(map[string][]time.Duration)
+	for _, data := range prs {
+		for user, reviews := range data.userReviews {
+			if len(reviews) > 0 {
+				intervals[user] = append(intervals[user], getCadenceIntervals(getTimedComments(data, reviews[0].UserID), getSubmittedTimes(reviews))...)
+			}
+		}
+	}
+
+	sessionGaps := make(map[string]time.Duration, len(intervals))
+	for user, userIntervals := range intervals {
+		sessionGaps[user] = adaptiveSessionGap(userIntervals, percentile)
+	}
+	for _, data := range prs {
+		data.sessionGaps = sessionGaps
+	}
+}
+
+// Returns the intervals between the reviewer's comments and review submissions on a PR. Comments posted together say
+// nothing about the cadence and are left out.
+func getCadenceIntervals(comments []*gitclient.PullRequestComment, reviewsSubmittedAt []time.Time) []time.Duration {
+	dateTimes := append([]time.Time{}, reviewsSubmittedAt...)
+	for _, comment := range comments {
+		dateTimes = append(dateTimes, *comment.CreatedAt)
+	}
+	sort.Slice(dateTimes, func(i, j int) bool { return dateTimes[i].Before(dateTimes[j]) })
+
+	var intervals []time.Duration
+	for i := 1; i < len(dateTimes); i++ {
+		if interval := dateTimes[i].Sub(dateTimes[i-1]); interval > 0 {
+			intervals = append(intervals, interval)
+		}
+	}
+	return intervals
+}
+
+// Returns the percentile of the intervals of a reviewer over all their PRs, so that reviewers who space their comments
+// out keep one session. With fewer than minCadenceIntervals intervals, DefaultSessionGap is returned.
+func adaptiveSessionGap(intervals []time.Duration, percentile float64) time.Duration {
+	if len(intervals) < minCadenceIntervals {
+		return DefaultSessionGap
+	}
+
+	// Nearest-rank percentile
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	rank := int(math.Ceil(percentile / 100 * float64(len(intervals))))
+	return intervals[min(max(rank, 1), len(intervals))-1]
+}
+
+// Reports whether there are comments and all of them were created at the submission of the review.
+func isBatchedAtSubmission(reviewComments []*gitclient.PullRequestComment, reviewSubmittedAt time.Time) bool {
+	for _, comment := range reviewComments {
+		if !comment.CreatedAt.Equal(reviewSubmittedAt) {
+			return false
+		}
+	}
+	return len(reviewComments) > 0
+}
 
+// CalculateTotalPeriodLength computes the total duration of all periods, splitting them at pauses longer than sessionGap.
+func CalculateTotalCommentPeriodLength(reviewComments []*gitclient.PullRequestComment, reviewSubmittedAt time.Time, sessionGap time.Duration) time.Duration {
 	if len(reviewComments) == 0 {
-		return minDuration
+		return minReviewDuration
 	}
 
+	return CalculateTotalSessionLength(reviewComments, []time.Time{reviewSubmittedAt}, sessionGap)
+}
+
+// CalculateTotalSessionLength computes the total duration of all periods formed by the comments and the review submissions together,
+// regardless of the review each comment belongs to. This lets comments spanning several review rounds form one continuous session.
+func CalculateTotalSessionLength(comments []*gitclient.PullRequestComment, reviewsSubmittedAt []time.Time, sessionGap time.Duration) time.Duration {
 	// Create a copy of the input slice to ensure the original is not modified
-	dateTimes := make([]time.Time, 0, len(reviewComments)+1) // Preallocate slice with the expected size
+	dateTimes := make([]time.Time, 0, len(comments)+len(reviewsSubmittedAt)) // Preallocate slice with the expected size
 
-	dateTimes = append(dateTimes, reviewSubmittedAt)
+	dateTimes = append(dateTimes, reviewsSubmittedAt...)
 
-	for _, comment := range reviewComments {
+	for _, comment := range comments {
 		dateTimes = append(dateTimes, *comment.CreatedAt)
 	}
 
+	if len(dateTimes) == 0 {
+		return minReviewDuration
+	}
+
 	// Sort the input array to ensure chronological order
 	sort.Slice(dateTimes, func(i, j int) bool {
 		return dateTimes[i].Before(dateTimes[j])
@@ -209,11 +1903,11 @@ func CalculateTotalCommentPeriodLength(reviewComments []*gitclient.PullRequestCo
 		// Calculate the time difference from the previous item
 		diff := dateTimes[i].Sub(dateTimes[i-1])
 
-		if diff <= 30*time.Minute {
+		if diff <= sessionGap {
 			// Part of the same period, update the end time
 			end = dateTimes[i]
 		} else {
-			// Time difference > 30 minutes, calculate the current period duration
+			// Time difference > sessionGap, calculate the current period duration
 			totalDuration += end.Sub(start)
 			// Start a new period
 			start = dateTimes[i]
@@ -224,22 +1918,140 @@ func CalculateTotalCommentPeriodLength(reviewComments []*gitclient.PullRequestCo
 	// Add the last period duration
 	totalDuration += end.Sub(start)
 
-	if totalDuration <= minDuration {
-		return minDuration
+	if totalDuration <= minReviewDuration {
+		return minReviewDuration
 	} else {
 		return totalDuration
 	}
 }
 
-// New helper function to find if a comment is addressed by a commit
+// Marks the comments created before a force-push as position-unstable, since the force-push rewrites the diff their positions refer to.
+func markPositionUnstableComments(comments []*gitclient.PullRequestComment, events []*gitclient.TimelineEvent) {
+	for _, event := range events {
+		if event.Event != gitclient.TimelineEventForcePushed || event.CreatedAt == nil {
+			continue
+		}
+
+		for _, comment := range comments {
+			if comment.CreatedAt.Before(*event.CreatedAt) {
+				comment.PositionUnstable = true
+			}
+		}
+	}
+}
+
+// Reports whether the commit file is the file at path, also when the commit renamed it
+func isSameFile(file *gitclient.RepositoryCommitFile, path *string) bool {
+	if path == nil {
+		return false
+	}
+	return (file.Filename != nil && *file.Filename == *path) || (file.PreviousFilename != nil && *file.PreviousFilename == *path)
+}
+
+// Reports whether the commit changed the file of the comment and, when the commented lines are known, those lines.
 func isCommentAddressedByCommit(comment *gitclient.PullRequestComment, commit *gitclient.RepositoryCommit) bool {
 	for _, file := range commit.Files {
-		if file.Filename == comment.Path { // Same file as the comment
+		if isSameFile(file, comment.Path) {
+			// The lines can't be trusted after a force-push, are unknown, or are lines of the base that later commits
+			// don't number, so any change to the file counts
+			if comment.PositionUnstable || comment.OriginalLine == nil || comment.Side == gitclient.SideLeft {
+				return true
+			}
+
 			// Check if the lines in the comment are affected in the commit
-			if *file.Patch != "" && strings.Contains(*file.Patch, fmt.Sprintf("@@ -%d", comment.OriginalPosition)) {
+			first, last := commentedLines(comment)
+			if file.Patch != nil && patchTouchesLines(*file.Patch, first, last) {
 				return true
 			}
 		}
 	}
 	return false
 }
+
+// Returns the first and last line the comment is on, in the file as of the commented commit
+func commentedLines(comment *gitclient.PullRequestComment) (int, int) {
+	if comment.OriginalStartLine != nil {
+		return *comment.OriginalStartLine, *comment.OriginalLine
+	}
+	return *comment.OriginalLine, *comment.OriginalLine
+}
+
+// Hunk header of a unified diff, with the start and the optional length of the old and of the new lines
+var hunkHeader = regexp.MustCompile(`(?m)^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Lines a hunk of a unified diff spans in the old and in the new file
+type hunk struct {
+	oldFirst, oldLast int
+	newFirst, newLast int
+}
+
+// Returns the hunks of the patch
+func parseHunks(patch string) []hunk {
+	matches := hunkHeader.FindAllStringSubmatch(patch, -1)
+	hunks := make([]hunk, len(matches))
+	for i, match := range matches {
+		hunks[i].oldFirst, hunks[i].oldLast = hunkLines(match[1], match[2])
+		hunks[i].newFirst, hunks[i].newLast = hunkLines(match[3], match[4])
+	}
+	return hunks
+}
+
+// Returns the first and last line of one side of a hunk from the start and the optional length in its header. A
+// side without lines spans its start line, the one the other side inserts after or deletes before.
+func hunkLines(startValue, lengthValue string) (int, int) {
+	start, _ := strconv.Atoi(startValue)
+	length := 1
+	if lengthValue != "" {
+		length, _ = strconv.Atoi(lengthValue)
+	}
+
+	if length == 0 {
+		return start, start
+	}
+	return start, start + length - 1
+}
+
+// Reports whether a hunk of the patch replaces or inserts next to the lines from first to last of the old file. This
+// assumes the lines weren't shifted by commits in between, which holds for the commit right after the comment.
+func patchTouchesLines(patch string, first, last int) bool {
+	for _, h := range parseHunks(patch) {
+		if h.oldFirst <= last && h.oldLast >= first {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the hunks of the patches of the changed files and how many of them the comments are on. The comments are
+// matched by the lines of the commented commit, so comments moved by later pushes may miss their hunk, and those
+// without known lines match none.
+func hunkCoverage(patches []*gitclient.RepositoryCommitFile, comments []*gitclient.PullRequestComment) (int, int) {
+	total, commented := 0, 0
+	for _, file := range patches {
+		if file.Filename == nil || file.Patch == nil {
+			continue
+		}
+
+		for _, h := range parseHunks(*file.Patch) {
+			total++
+			for _, comment := range comments {
+				if comment.Path == nil || *comment.Path != *file.Filename || comment.OriginalLine == nil {
+					continue
+				}
+
+				// Comments on the base number the old lines, the others the new ones
+				hunkFirst, hunkLast := h.newFirst, h.newLast
+				if comment.Side == gitclient.SideLeft {
+					hunkFirst, hunkLast = h.oldFirst, h.oldLast
+				}
+
+				first, last := commentedLines(comment)
+				if hunkFirst <= last && hunkLast >= first {
+					commented++
+					break
+				}
+			}
+		}
+	}
+	return total, commented
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"src/gitclient"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReduceMetrics_CombinesPartials(t *testing.T) {
+	partial1 := map[string]*ContributorMetrics{
+		"reviewer1": {PRsReviewed: 1, TotalComments: 2, TotalTimeToFirstReview: 1 * time.Hour, reviewsSubmitted: 1, ApprovalsGiven: 1, reviewDays: map[string]struct{}{"2025-01-06": {}}},
+	}
+	partial2 := map[string]*ContributorMetrics{
+		"reviewer1": {PRsReviewed: 1, TotalComments: 4, TotalTimeToFirstReview: 3 * time.Hour, reviewsSubmitted: 2, reviewDays: map[string]struct{}{"2025-01-06": {}, "2025-01-07": {}}},
+		"reviewer2": {PRsReviewed: 1, reviewsSubmitted: 1, reviewDays: map[string]struct{}{"2025-01-07": {}}},
+	}
+
+	metrics := make(map[string]*ContributorMetrics)
+	reduceMetrics(metrics, partial1)
+	reduceMetrics(metrics, partial2)
+	finalizeMetrics(metrics)
+
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, 2, metrics["reviewer1"].PRsReviewed)
+	assert.Equal(t, 6, metrics["reviewer1"].TotalComments)
+	assert.Equal(t, 3.0, metrics["reviewer1"].AverageCommentsPerReview)
+	assert.Equal(t, 2*time.Hour, metrics["reviewer1"].AverageTimeToFirstReview)
+	assert.Equal(t, 1.0, metrics["reviewer1"].ReviewsPerActiveDay)
+	assert.InDelta(t, 1.0/3.0, metrics["reviewer1"].ApprovalRate, 1e-9)
+	assert.Equal(t, 1, metrics["reviewer2"].PRsReviewed)
+
+	// The partials themselves are left untouched
+	assert.Equal(t, 1, partial1["reviewer1"].PRsReviewed)
+	assert.Len(t, partial1["reviewer1"].reviewDays, 1)
+}
+
+func TestIsCommentAddressedByCommit_MatchesFileLines(t *testing.T) {
+	// The comment is on line 120 of the file, which used to be its 10th position in the diff
+	comment := &gitclient.PullRequestComment{Path: github.String("file.go"), OriginalLine: github.Int(120), Side: gitclient.SideRight}
+	commit := func(patch string) *gitclient.RepositoryCommit {
+		return &gitclient.RepositoryCommit{Files: []*gitclient.RepositoryCommitFile{{Filename: github.String("file.go"), Patch: github.String(patch)}}}
+	}
+
+	// A change far above the comment no longer matches just because its hunk starts at the diff position
+	assert.False(t, isCommentAddressedByCommit(comment, commit("@@ -10,7 +10,9 @@ func main() {")))
+	assert.True(t, isCommentAddressedByCommit(comment, commit("@@ -10,7 +10,9 @@ func main() {\n@@ -118,6 +120,8 @@ func run() {")))
+	assert.True(t, isCommentAddressedByCommit(comment, commit("@@ -120 +120 @@")))
+
+	// Multi-line comments match a change to any of their lines
+	comment.OriginalStartLine = github.Int(100)
+	assert.True(t, isCommentAddressedByCommit(comment, commit("@@ -95,6 +95,6 @@")))
+
+	// Lines of the base aren't numbered by later commits, so any change to the file counts
+	left := &gitclient.PullRequestComment{Path: github.String("file.go"), OriginalLine: github.Int(120), Side: gitclient.SideLeft}
+	assert.True(t, isCommentAddressedByCommit(left, commit("@@ -10,7 +10,9 @@")))
+}
@@ -1,7 +1,12 @@
 package metrics_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"os"
 	"testing"
 	"time"
 
@@ -23,21 +28,66 @@ func (m *MockGitClient) GetPullRequests(owner, repo string, dateFrom, dateTo tim
 	return args.Get(0).([]*gitclient.PullRequest), args.Error(1)
 }
 
+func (m *MockGitClient) GetPullRequestsUpdated(owner, repo string, dateFrom, dateTo time.Time) ([]*gitclient.PullRequest, error) {
+	args := m.Called(owner, repo, dateFrom, dateTo)
+	return args.Get(0).([]*gitclient.PullRequest), args.Error(1)
+}
+
+func (m *MockGitClient) GetIssueComments(owner, repo string, prNumber int) ([]*gitclient.IssueComment, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).([]*gitclient.IssueComment), args.Error(1)
+}
+
 func (m *MockGitClient) GetReviews(owner, repo string, prNumber int) ([]*gitclient.PullRequestReview, error) {
 	args := m.Called(owner, repo, prNumber)
 	return args.Get(0).([]*gitclient.PullRequestReview), args.Error(1)
 }
 
+func (m *MockGitClient) GetReviewEdits(owner, repo string, prNumber int) (map[int64]time.Time, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).(map[int64]time.Time), args.Error(1)
+}
+
 func (m *MockGitClient) GetComments(owner, repo string, prNumber int) ([]*gitclient.PullRequestComment, error) {
 	args := m.Called(owner, repo, prNumber)
 	return args.Get(0).([]*gitclient.PullRequestComment), args.Error(1)
 }
 
-func (m *MockGitClient) GetCommits(owner, repo string, prNumber int, since time.Time, filtered bool) ([]*gitclient.RepositoryCommit, []error) {
-	args := m.Called(owner, repo, prNumber, since, filtered)
+func (m *MockGitClient) GetCommits(owner, repo string, prNumber int, since time.Time, paths []string) ([]*gitclient.RepositoryCommit, []error) {
+	args := m.Called(owner, repo, prNumber, since, paths)
 	return args.Get(0).([]*gitclient.RepositoryCommit), []error{}
 }
 
+func (m *MockGitClient) GetTimelineEvents(owner, repo string, prNumber int) ([]*gitclient.TimelineEvent, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).([]*gitclient.TimelineEvent), args.Error(1)
+}
+
+func (m *MockGitClient) GetLineStats(owner, repo string, prNumber int) (*gitclient.LineStats, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).(*gitclient.LineStats), args.Error(1)
+}
+
+func (m *MockGitClient) GetReviewThreads(owner, repo string, prNumber int) ([]*gitclient.ReviewThread, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).([]*gitclient.ReviewThread), args.Error(1)
+}
+
+func (m *MockGitClient) GetChangedFiles(owner, repo string, prNumber int) ([]string, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetChangedFilePatches(owner, repo string, prNumber int) ([]*gitclient.RepositoryCommitFile, error) {
+	args := m.Called(owner, repo, prNumber)
+	return args.Get(0).([]*gitclient.RepositoryCommitFile), args.Error(1)
+}
+
+func (m *MockGitClient) GetCommentReactions(owner, repo string, commentID int64) ([]*gitclient.Reaction, error) {
+	args := m.Called(owner, repo, commentID)
+	return args.Get(0).([]*gitclient.Reaction), args.Error(1)
+}
+
 func (m *MockGitClient) GetApiRateUsed() int {
 	return m.Called().Int(0)
 }
@@ -46,6 +96,45 @@ func (m *MockGitClient) GetApiRateRemaining() int {
 	return m.Called().Int(0)
 }
 
+func (m *MockGitClient) GetPullRequestsFetched() int {
+	return m.Called().Int(0)
+}
+
+func (m *MockGitClient) GetApiCallsByOperation() map[string]int {
+	return m.Called().Get(0).(map[string]int)
+}
+
+func (m *MockGitClient) GetAuthenticatedUser() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) GetOrgRepos(org string, filter gitclient.RepoFilter) ([]string, error) {
+	args := m.Called(org, filter)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetTeamMembers(org string, teamSlug string) ([]string, error) {
+	args := m.Called(org, teamSlug)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetRateLimitStatus() (*gitclient.RateLimitStatus, error) {
+	args := m.Called()
+	return args.Get(0).(*gitclient.RateLimitStatus), args.Error(1)
+}
+
+// Registers the mock expectations for fetching a single pull request's reviews and comments.
+func setupPullRequestMocks(mockClient *MockGitClient, repo string, prNumber int, reviews []*gitclient.PullRequestReview, comments []*gitclient.PullRequestComment) {
+	mockClient.On("GetReviews", "owner", repo, prNumber).Return(reviews, nil)
+	mockClient.On("GetComments", "owner", repo, prNumber).Return(comments, nil)
+	mockClient.On("GetLineStats", "owner", repo, prNumber).Return(&gitclient.LineStats{}, nil)
+	if len(comments) > 0 {
+		mockClient.On("GetCommits", "owner", repo, prNumber, *comments[0].CreatedAt, mock.Anything).Return([]*gitclient.RepositoryCommit{}, nil)
+		mockClient.On("GetTimelineEvents", "owner", repo, prNumber).Return([]*gitclient.TimelineEvent{}, nil)
+	}
+}
+
 func TestCalculateMetrics_Success(t *testing.T) {
 	mockClient := new(MockGitClient)
 
@@ -77,7 +166,7 @@ func TestCalculateMetrics_Success(t *testing.T) {
 			UserID:              11,
 			Path:                github.String("file.go"),
 			CreatedAt:           &dateTo,
-			OriginalPosition:    10,
+			OriginalLine:        github.Int(10),
 		},
 	}
 
@@ -94,12 +183,14 @@ func TestCalculateMetrics_Success(t *testing.T) {
 	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
 	mockClient.On("GetReviews", "owner", "repo", 1).Return(mockReviews, nil)
 	mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
-	mockClient.On("GetCommits", "owner", "repo", 1, *mockComments[0].CreatedAt, true).Return(mockCommits, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	mockClient.On("GetCommits", "owner", "repo", 1, *mockComments[0].CreatedAt, []string{"file.go"}).Return(mockCommits, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{}, nil)
 	mockClient.On("GetApiRateUsed").Return(10)
 	mockClient.On("GetApiRateRemaining").Return(90)
 
 	// Call the method
-	metricsResult, errs := metrics.CalculateMetrics(mockClient, "owner", "repo", dateFrom, dateTo)
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
 
 	// Assertions
 	assert.Len(t, errs, 0)
@@ -124,7 +215,7 @@ func TestCalculateMetrics_ErrorFetchingPullRequests(t *testing.T) {
 	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return([]*gitclient.PullRequest{}, errors.New("failed to fetch PRs"))
 
 	// Call the method
-	metricsResult, errs := metrics.CalculateMetrics(mockClient, "owner", "repo", dateFrom, dateTo)
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
 
 	// Assertions
 	assert.Nil(t, metricsResult)
@@ -152,15 +243,2406 @@ func TestCalculateMetrics_ErrorFetchingReviews(t *testing.T) {
 
 	// Set up mock expectations
 	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
-	mockClient.On("GetReviews", "owner", "repo", 1).Return(mockPullRequestReviews, errors.New("failed to fetch reviews"))
+	fetchErr := errors.New("failed to fetch reviews")
+	mockClient.On("GetReviews", "owner", "repo", 1).Return(mockPullRequestReviews, fetchErr)
 	mockClient.On("GetApiRateUsed").Return(1)
 	mockClient.On("GetApiRateRemaining").Return(4999)
 
 	// Call the method
-	metricsResult, errs := metrics.CalculateMetrics(mockClient, "owner", "repo", dateFrom, dateTo)
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
 
-	// Assertions
+	// Assertions, the error tells which PR and call failed
 	assert.Nil(t, metricsResult)
 	assert.Len(t, errs, 1)
-	assert.EqualError(t, errs[0], "failed to fetch reviews")
+	assert.EqualError(t, errs[0], "GetReviews of owner/repo#1: failed to fetch reviews")
+
+	var metricsErr *metrics.MetricsError
+	assert.True(t, errors.As(errs[0], &metricsErr))
+	assert.Equal(t, "owner/repo", metricsErr.Repo)
+	assert.Equal(t, 1, metricsErr.PRNumber)
+	assert.Equal(t, "GetReviews", metricsErr.Operation)
+	assert.ErrorIs(t, errs[0], fetchErr)
+}
+
+func TestCalculateMetrics_ReviewsPerActiveDay(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	day1Morning := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	day1Evening := time.Date(2025, 1, 6, 18, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 7, 11, 0, 0, 0, time.UTC)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 3, Title: github.String("PR 3"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	submittedAt := map[int]*time.Time{1: &day1Morning, 2: &day1Evening, 3: &day2}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for prNumber, submitted := range submittedAt {
+		mockClient.On("GetReviews", "owner", "repo", prNumber).Return([]*gitclient.PullRequestReview{
+			{ID: int64(prNumber), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: submitted},
+		}, nil)
+		mockClient.On("GetComments", "owner", "repo", prNumber).Return([]*gitclient.PullRequestComment{}, nil)
+		mockClient.On("GetLineStats", "owner", "repo", prNumber).Return(&gitclient.LineStats{}, nil)
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	reviewerMetrics := metricsResult["reviewer1"]
+	assert.NotNil(t, reviewerMetrics)
+	assert.Equal(t, 3, reviewerMetrics.PRsReviewed)
+	assert.Equal(t, 1.5, reviewerMetrics.ReviewsPerActiveDay)
+}
+
+func TestCalculateMetrics_FilterByLabel(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Fix crash"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), Labels: []string{"bug"}},
+		{Number: 2, Title: github.String("Add button"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), Labels: []string{"feature"}},
+		{Number: 3, Title: github.String("Update docs"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations. Only the PR labeled "bug" may be fetched.
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, nil)
+	mockClient.On("GetComments", "owner", "repo", 1).Return([]*gitclient.PullRequestComment{}, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{Label: "bug"})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Len(t, metricsResult, 1)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	mockClient.AssertNotCalled(t, "GetReviews", "owner", "repo", 2)
+	mockClient.AssertNotCalled(t, "GetReviews", "owner", "repo", 3)
+}
+
+func TestCalculateMetricsByLabel(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Fix crash"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), Labels: []string{"bug"}},
+		{Number: 2, Title: github.String("Fix and extend"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), Labels: []string{"bug", "feature"}},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, pr := range mockPullRequests {
+		mockClient.On("GetReviews", "owner", "repo", pr.Number).Return([]*gitclient.PullRequestReview{
+			{ID: int64(pr.Number), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+		}, nil)
+		mockClient.On("GetComments", "owner", "repo", pr.Number).Return([]*gitclient.PullRequestComment{}, nil)
+		mockClient.On("GetLineStats", "owner", "repo", pr.Number).Return(&gitclient.LineStats{}, nil)
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	results, errs := metrics.CalculateMetricsByLabel(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 2, results["bug"]["reviewer1"].PRsReviewed)
+	assert.Equal(t, 1, results["feature"]["reviewer1"].PRsReviewed)
+}
+
+func TestMerge_MatchesSinglePass(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	oneHourLater := createdAt.Add(1 * time.Hour)
+	fourHoursLater := createdAt.Add(4 * time.Hour)
+
+	pr1 := &gitclient.PullRequest{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")}
+	pr2 := &gitclient.PullRequest{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")}
+	pr3 := &gitclient.PullRequest{Number: 3, Title: github.String("PR 3"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")}
+
+	review := func(id int64, submittedAt *time.Time) []*gitclient.PullRequestReview {
+		return []*gitclient.PullRequestReview{{ID: id, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: submittedAt}}
+	}
+	pr3Comments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 3, UserID: 11, Path: github.String("a.go"), CreatedAt: &fourHoursLater},
+		{PullRequestReviewID: 3, UserID: 11, Path: github.String("b.go"), CreatedAt: &fourHoursLater},
+		{PullRequestReviewID: 3, UserID: 11, Path: github.String("c.go"), CreatedAt: &fourHoursLater},
+	}
+
+	// Single pass over all three PRs, in the repository the commented files of the split belong to
+	singleClient := new(MockGitClient)
+	singleClient.On("GetPullRequests", "owner", "repoB", dateFrom, dateTo).Return([]*gitclient.PullRequest{pr1, pr2, pr3}, nil)
+	setupPullRequestMocks(singleClient, "repoB", 1, review(1, &oneHourLater), []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(singleClient, "repoB", 2, review(2, &oneHourLater), []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(singleClient, "repoB", 3, review(3, &fourHoursLater), pr3Comments)
+	singleClient.On("GetApiRateUsed").Return(10)
+	singleClient.On("GetApiRateRemaining").Return(90)
+
+	// Two repositories splitting the PRs 2:1
+	splitClient := new(MockGitClient)
+	splitClient.On("GetPullRequests", "owner", "repoA", dateFrom, dateTo).Return([]*gitclient.PullRequest{pr1, pr2}, nil)
+	splitClient.On("GetPullRequests", "owner", "repoB", dateFrom, dateTo).Return([]*gitclient.PullRequest{pr3}, nil)
+	setupPullRequestMocks(splitClient, "repoA", 1, review(1, &oneHourLater), []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(splitClient, "repoA", 2, review(2, &oneHourLater), []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(splitClient, "repoB", 3, review(3, &fourHoursLater), pr3Comments)
+	splitClient.On("GetApiRateUsed").Return(10)
+	splitClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the methods
+	single, errs := metrics.CalculateMetrics(context.Background(), singleClient, "owner", "repoB", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	resultA, errs := metrics.CalculateMetrics(context.Background(), splitClient, "owner", "repoA", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	resultB, errs := metrics.CalculateMetrics(context.Background(), splitClient, "owner", "repoB", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+
+	merged := metrics.Merge(resultA, resultB)
+
+	// Assertions
+	assert.Equal(t, *single["reviewer1"], *merged["reviewer1"])
+	assert.Equal(t, 3, merged["reviewer1"].PRsReviewed)
+	assert.Equal(t, 1.0, merged["reviewer1"].AverageCommentsPerReview)
+	assert.Equal(t, 2*time.Hour, merged["reviewer1"].AverageTimeToFirstReview)
+
+	// The inputs keep their own averages
+	assert.Equal(t, 1*time.Hour, resultA["reviewer1"].AverageTimeToFirstReview)
+	assert.Equal(t, 4*time.Hour, resultB["reviewer1"].AverageTimeToFirstReview)
+}
+
+func TestCalculateMetrics_ForcePushMakesPositionUnstable(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	forcePushedAt := createdAt.Add(2 * time.Hour)
+	committedAt := createdAt.Add(3 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Refactor"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	mockReviews := []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt},
+	}
+	// The patch of the rewritten commit no longer touches the original line of the comment
+	mockCommits := []*gitclient.RepositoryCommit{
+		{CreatedAt: &committedAt, Files: []*gitclient.RepositoryCommitFile{
+			{Filename: github.String("file.go"), Patch: github.String("@@ -42,7 +42,9 @@")},
+		}},
+	}
+
+	run := func(events []*gitclient.TimelineEvent) *metrics.ContributorMetrics {
+		mockComments := []*gitclient.PullRequestComment{
+			{PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt, OriginalLine: github.Int(10)},
+		}
+
+		mockClient := new(MockGitClient)
+		mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+		mockClient.On("GetReviews", "owner", "repo", 1).Return(mockReviews, nil)
+		mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
+		mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+		mockClient.On("GetCommits", "owner", "repo", 1, commentedAt, []string{"file.go"}).Return(mockCommits, nil)
+		mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return(events, nil)
+		mockClient.On("GetApiRateUsed").Return(10)
+		mockClient.On("GetApiRateRemaining").Return(90)
+
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+		assert.Len(t, errs, 0)
+		return metricsResult["reviewer1"]
+	}
+
+	// Without a force-push the position doesn't match the patch
+	assert.Equal(t, 0.0, run([]*gitclient.TimelineEvent{}).PercentageCommentsLeadingToChanges)
+
+	// After a force-push the comment falls back to file-level matching
+	forcePush := []*gitclient.TimelineEvent{{Event: gitclient.TimelineEventForcePushed, CreatedAt: &forcePushedAt}}
+	assert.Equal(t, 100.0, run(forcePush).PercentageCommentsLeadingToChanges)
+}
+
+func TestCalculateMetrics_ApprovalRate(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	review := func(id int64, state string) *gitclient.PullRequestReview {
+		return &gitclient.PullRequestReview{ID: id, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: state}
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		review(1, gitclient.ReviewStateChangesRequested),
+		review(2, gitclient.ReviewStateApproved),
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		review(3, gitclient.ReviewStateCommented),
+		review(4, gitclient.ReviewStateApproved),
+		review(5, gitclient.ReviewStateApproved),
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 3, metricsResult["reviewer1"].ApprovalsGiven)
+	assert.Equal(t, 0.6, metricsResult["reviewer1"].ApprovalRate)
+}
+
+func TestCalculateMetrics_SessionAcrossReviews(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	firstRound := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	secondRound := firstRound.Add(10 * time.Minute)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	mockReviews := []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &firstRound},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &secondRound},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &firstRound},
+		{PullRequestReviewID: 2, UserID: 11, Path: github.String("file.go"), CreatedAt: &secondRound},
+	}
+
+	run := func(options metrics.Options) *metrics.ContributorMetrics {
+		mockClient := new(MockGitClient)
+		mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+		setupPullRequestMocks(mockClient, "repo", 1, mockReviews, mockComments)
+		mockClient.On("GetApiRateUsed").Return(10)
+		mockClient.On("GetApiRateRemaining").Return(90)
+
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+		assert.Len(t, errs, 0)
+		return metricsResult["reviewer1"]
+	}
+
+	// Per review, each round only gets the minimum duration
+	assert.Equal(t, 6*time.Minute, run(metrics.Options{}).AverageTimeToCompleteReview)
+
+	// Across reviews, the two rounds 10 minutes apart form one session
+	assert.Equal(t, 10*time.Minute, run(metrics.Options{SessionAcrossReviews: true}).AverageTimeToCompleteReview)
+}
+
+func TestCalculateMetrics_IssueComments(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	discussedAt := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	answeredAt := discussedAt.Add(5 * time.Minute)
+	discussedAgainAt := discussedAt.Add(10 * time.Minute)
+	commentedAt := discussedAt.Add(20 * time.Minute)
+	submittedAt := discussedAt.Add(30 * time.Minute)
+	followedUpAt := discussedAt.Add(60 * time.Minute)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	mockReviews := []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &submittedAt},
+	}
+
+	// reviewer1 discusses the PR before the review and follows up after it, the author answers in between
+	mockDiscussion := []*gitclient.IssueComment{
+		{ID: 101, UserID: 11, UserLogin: github.String("reviewer1"), CreatedAt: &discussedAt},
+		{ID: 102, UserID: 10, UserLogin: github.String("contributor1"), CreatedAt: &answeredAt},
+		{ID: 103, UserID: 11, UserLogin: github.String("reviewer1"), CreatedAt: &discussedAgainAt},
+		{ID: 104, UserID: 11, UserLogin: github.String("reviewer1"), CreatedAt: &followedUpAt},
+	}
+
+	run := func(options metrics.Options) *metrics.ContributorMetrics {
+		mockClient := new(MockGitClient)
+		mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+		setupPullRequestMocks(mockClient, "repo", 1, mockReviews, mockComments)
+		mockClient.On("GetIssueComments", "owner", "repo", 1).Return(mockDiscussion, nil)
+		mockClient.On("GetApiRateUsed").Return(10)
+		mockClient.On("GetApiRateRemaining").Return(90)
+
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+		assert.Len(t, errs, 0)
+		return metricsResult["reviewer1"]
+	}
+
+	// Only the review comments count without the discussion
+	assert.Equal(t, 10*time.Minute, run(metrics.Options{}).AverageTimeToCompleteReview)
+
+	// The discussion leading up to the review extends its session
+	withDiscussion := run(metrics.Options{IssueComments: true})
+	assert.Equal(t, 30*time.Minute, withDiscussion.AverageTimeToCompleteReview)
+	assert.Equal(t, 2, withDiscussion.TotalComments)
+
+	// Across reviews, the follow-up after the review continues the session too
+	assert.Equal(t, 60*time.Minute, run(metrics.Options{IssueComments: true, SessionAcrossReviews: true}).AverageTimeToCompleteReview)
+}
+
+func TestCalculateMetrics_CancelledMidRun(t *testing.T) {
+	mockClient := new(MockGitClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations. The run is cancelled while the first PR is processed.
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, nil).Run(func(args mock.Arguments) { cancel() })
+	mockClient.On("GetComments", "owner", "repo", 1).Return([]*gitclient.PullRequestComment{}, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(ctx, mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Len(t, metricsResult, 1)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	mockClient.AssertNotCalled(t, "GetReviews", "owner", "repo", 2)
+}
+
+func TestCalculateMetrics_CommentDensity(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("b.go"), CreatedAt: &dateTo},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, nil)
+	mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{Additions: 100, Deletions: 50, ChangedFiles: 2}, nil)
+	mockClient.On("GetCommits", "owner", "repo", 1, dateTo, []string{"a.go", "b.go"}).Return([]*gitclient.RepositoryCommit{}, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 150, metricsResult["reviewer1"].TotalLinesReviewed)
+	assert.Equal(t, 0.02, metricsResult["reviewer1"].CommentDensity)
+}
+
+func TestCalculateMetrics_CommentDensityWithoutLines(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 0, metricsResult["reviewer1"].TotalLinesReviewed)
+	assert.Equal(t, 0.0, metricsResult["reviewer1"].CommentDensity)
+}
+
+func TestCalculateMetrics_SLAComplianceRate(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	withinSLA := createdAt.Add(5 * time.Hour)
+	atSLA := createdAt.Add(24 * time.Hour)
+	outsideSLA := createdAt.Add(30 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 3, Title: github.String("PR 3"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 4, Title: github.String("PR 4"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for prNumber, submittedAt := range map[int]*time.Time{1: &withinSLA, 2: &atSLA, 3: &outsideSLA, 4: &outsideSLA} {
+		setupPullRequestMocks(mockClient, "repo", prNumber, []*gitclient.PullRequestReview{
+			{ID: int64(prNumber), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: submittedAt},
+		}, []*gitclient.PullRequestComment{})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{SLA: 24 * time.Hour})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 0.5, metricsResult["reviewer1"].SLAComplianceRate)
+	assert.Equal(t, 0.5, metrics.TeamSLABreachRate(metricsResult, 24*time.Hour))
+	assert.Equal(t, 0.0, metrics.TeamSLABreachRate(metricsResult, 0))
+}
+
+func TestCalculateMetricsByMonth(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 2, 28, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 30, 8, 0, 0, 0, time.UTC)
+	january := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+	february := time.Date(2025, 2, 3, 10, 0, 0, 0, time.UTC)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations. PR 1 gets reviews in both months.
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &january, State: gitclient.ReviewStateChangesRequested},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &february, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &february, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	results, errs := metrics.CalculateMetricsByMonth(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Len(t, results["reviewer1"], 2)
+	assert.Equal(t, 1, results["reviewer1"]["2025-01"].PRsReviewed)
+	assert.Equal(t, 0, results["reviewer1"]["2025-01"].ApprovalsGiven)
+	assert.Equal(t, 2, results["reviewer1"]["2025-02"].PRsReviewed)
+	assert.Equal(t, 2, results["reviewer1"]["2025-02"].ApprovalsGiven)
+}
+
+func TestCalculateMetrics_InstantApprovals(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	requestedAt := createdAt.Add(1 * time.Hour)
+	instantApproval := requestedAt.Add(10 * time.Second)
+	laterApproval := requestedAt.Add(2 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 3, Title: github.String("PR 3"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	reviewRequested := []*gitclient.TimelineEvent{
+		{Event: gitclient.TimelineEventReviewRequested, CreatedAt: &requestedAt, ReviewerLogin: github.String("reviewer1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &instantApproval, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &laterApproval, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	// A fast approval with a comment isn't a rubber stamp
+	setupPullRequestMocks(mockClient, "repo", 3, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &instantApproval, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 3, UserID: 11, Path: github.String("file.go"), CreatedAt: &instantApproval},
+	})
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return(reviewRequested, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 2).Return(reviewRequested, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 3).Return(reviewRequested, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{InstantApprovalThreshold: 1 * time.Minute})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 3, metricsResult["reviewer1"].ApprovalsGiven)
+	assert.Equal(t, 1, metricsResult["reviewer1"].InstantApprovals)
+}
+
+func TestCalculateMetrics_DistinctFilesCommented(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("b.go"), CreatedAt: &dateTo},
+	})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 2, UserID: 11, Path: github.String("b.go"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 2, UserID: 11, Path: github.String("c.go"), CreatedAt: &dateTo},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 5, metricsResult["reviewer1"].TotalComments)
+	assert.Equal(t, 3, metricsResult["reviewer1"].DistinctFilesCommented)
+}
+
+func TestCalculateMetrics_NilOriginalLine(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	committedAt := createdAt.Add(2 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	// The comment refers to a file that was removed afterwards, so it has no position
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("removed.go"), CreatedAt: &commentedAt},
+	}
+	mockCommits := []*gitclient.RepositoryCommit{
+		{CreatedAt: &committedAt, Files: []*gitclient.RepositoryCommitFile{
+			{Filename: github.String("removed.go"), Patch: github.String("@@ -1,20 +0,0 @@")},
+		}},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt},
+	}, nil)
+	mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	mockClient.On("GetCommits", "owner", "repo", 1, commentedAt, []string{"removed.go"}).Return(mockCommits, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 100.0, metricsResult["reviewer1"].PercentageCommentsLeadingToChanges)
+}
+
+func TestCalculateMetrics_NullReviewID(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// The second comment isn't attached to a review, and the second review comes without an ID
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt},
+		{UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &commentedAt},
+		{UserID: 11, Path: github.String("b.go"), CreatedAt: &commentedAt},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].TotalComments)
+	assert.Equal(t, 1, metricsResult["reviewer1"].DistinctFilesCommented)
+}
+
+func TestCalculateMetricsByMergedState(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Shipped"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateMerged},
+		{Number: 2, Title: github.String("Also shipped"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateMerged},
+		{Number: 3, Title: github.String("Abandoned"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateClosedUnmerged},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, pr := range mockPullRequests {
+		setupPullRequestMocks(mockClient, "repo", pr.Number, []*gitclient.PullRequestReview{
+			{ID: int64(pr.Number), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+		}, []*gitclient.PullRequestComment{})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	results, errs := metrics.CalculateMetricsByMergedState(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Len(t, results, 2)
+	assert.Equal(t, 2, results[gitclient.MergedStateMerged]["reviewer1"].PRsReviewed)
+	assert.Equal(t, 1, results[gitclient.MergedStateClosedUnmerged]["reviewer1"].PRsReviewed)
+}
+
+func TestCalculateMetrics_DaysActiveInRange(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	firstReview := time.Date(2025, 1, 6, 18, 0, 0, 0, time.UTC)
+	middleReview := time.Date(2025, 1, 8, 12, 0, 0, 0, time.UTC)
+	lastReview := time.Date(2025, 1, 11, 9, 0, 0, 0, time.UTC)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &middleReview},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &lastReview},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &firstReview},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), metricsResult["reviewer1"].FirstReviewDate)
+	assert.Equal(t, 5, metricsResult["reviewer1"].DaysActiveInRange)
+}
+
+func TestCalculateMetrics_PerCommentDuration(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	submittedAt := createdAt.Add(1 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	// All comments were batched and posted together with the review
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &submittedAt},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &submittedAt},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("b.go"), CreatedAt: &submittedAt},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("c.go"), CreatedAt: &submittedAt},
+	}
+
+	run := func(options metrics.Options) *metrics.ContributorMetrics {
+		mockClient := new(MockGitClient)
+		mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+		setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+			{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		}, mockComments)
+		mockClient.On("GetApiRateUsed").Return(10)
+		mockClient.On("GetApiRateRemaining").Return(90)
+
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+		assert.Len(t, errs, 0)
+		return metricsResult["reviewer1"]
+	}
+
+	// The sessions collapse to the minimum review duration
+	assert.Equal(t, 3*time.Minute, run(metrics.Options{}).AverageTimeToCompleteReview)
+
+	// The estimate is based on the number of comments instead
+	assert.Equal(t, 8*time.Minute, run(metrics.Options{PerCommentDuration: 2 * time.Minute}).AverageTimeToCompleteReview)
+}
+
+func TestCalculateMetrics_RateLimitReachedStopsCleanly(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, the floor is reached while fetching the second PR
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetReviews", "owner", "repo", 2).Return([]*gitclient.PullRequestReview{}, fmt.Errorf("%w and will be reset in 10m0s", gitclient.ErrRateLimitReached))
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+}
+
+func TestCalculateMetrics_CrossTeamReviewShare(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Same team"), CreatedAt: &dateFrom, UserLogin: github.String("backend1")},
+		{Number: 2, Title: github.String("Other team"), CreatedAt: &dateFrom, UserLogin: github.String("frontend1")},
+		{Number: 3, Title: github.String("Unknown team"), CreatedAt: &dateFrom, UserLogin: github.String("contractor1")},
+	}
+	teams := map[string][]string{
+		"backend":  {"backend1", "backend2"},
+		"frontend": {"frontend1"},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, pr := range mockPullRequests {
+		setupPullRequestMocks(mockClient, "repo", pr.Number, []*gitclient.PullRequestReview{
+			{ID: int64(pr.Number), UserID: 12, UserLogin: github.String("backend2"), SubmittedAt: &dateTo},
+		}, []*gitclient.PullRequestComment{})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{Teams: teams})
+
+	// Assertions, the PR of the author without a team is not classified
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 3, metricsResult["backend2"].PRsReviewed)
+	assert.Equal(t, 0.5, metricsResult["backend2"].CrossTeamReviewShare)
+}
+
+func TestCalculateMetrics_FromReadyForReview(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	readyAt := createdAt.Add(2 * time.Hour)
+	submittedAt := createdAt.Add(3 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Draft first"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	run := func(options metrics.Options) *metrics.ContributorMetrics {
+		mockClient := new(MockGitClient)
+		mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+		setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+			{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		}, []*gitclient.PullRequestComment{})
+		mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{
+			{Event: gitclient.TimelineEventReadyForReview, CreatedAt: &readyAt},
+		}, nil)
+		mockClient.On("GetApiRateUsed").Return(10)
+		mockClient.On("GetApiRateRemaining").Return(90)
+
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+		assert.Len(t, errs, 0)
+		return metricsResult["reviewer1"]
+	}
+
+	// By default the clock starts at creation
+	assert.Equal(t, 3*time.Hour, run(metrics.Options{}).AverageTimeToFirstReview)
+
+	// The two hours as a draft are excluded
+	assert.Equal(t, 1*time.Hour, run(metrics.Options{FromReadyForReview: true}).AverageTimeToFirstReview)
+}
+
+func TestCalculateMetrics_AcknowledgedComments(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{ID: 101, PullRequestReviewID: 1, UserID: 11, UserLogin: github.String("reviewer1"), Path: github.String("a.go"), CreatedAt: &dateTo, ReactionCount: 1},
+		{ID: 102, PullRequestReviewID: 1, UserID: 11, UserLogin: github.String("reviewer1"), Path: github.String("a.go"), CreatedAt: &dateTo, ReactionCount: 1},
+		{ID: 103, PullRequestReviewID: 1, UserID: 11, UserLogin: github.String("reviewer1"), Path: github.String("b.go"), CreatedAt: &dateTo},
+	}
+
+	// Set up mock expectations, only the comments with reactions are looked up
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, mockComments)
+	mockClient.On("GetCommentReactions", "owner", "repo", int64(101)).Return([]*gitclient.Reaction{
+		{UserLogin: github.String("contributor1"), Content: "+1"},
+	}, nil)
+	mockClient.On("GetCommentReactions", "owner", "repo", int64(102)).Return([]*gitclient.Reaction{
+		{UserLogin: github.String("reviewer2"), Content: "+1"},
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{Acknowledgements: true})
+
+	// Assertions, the reaction of another reviewer doesn't count
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].AcknowledgedComments)
+	mockClient.AssertNotCalled(t, "GetCommentReactions", "owner", "repo", int64(103))
+}
+
+func TestCalculateMetrics_ReviewsByHourInUserTimezone(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	submittedAt := time.Date(2025, 1, 6, 0, 30, 0, 0, time.UTC) // 9:30 in Tokyo
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &submittedAt},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	options := metrics.Options{UserLocations: map[string]*time.Location{"reviewer1": tokyo}}
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+
+	// Assertions, reviewer2 falls back to UTC
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].ReviewsByHour[9])
+	assert.Equal(t, 0, metricsResult["reviewer1"].ReviewsByHour[0])
+	assert.Equal(t, 1, metricsResult["reviewer2"].ReviewsByHour[0])
+}
+
+func TestCalculateMetrics_CoAuthoredReviews(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+	reviewerID := int64(11)
+
+	// Set up mock expectations, reviewer1 pushed a commit to the PR
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetCommits", "owner", "repo", 1, dateFrom, []string(nil)).Return([]*gitclient.RepositoryCommit{
+		{CreatedAt: &dateFrom, AuthorID: &reviewerID},
+		{CreatedAt: &dateFrom},
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Detecting only counts the co-authored reviews
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{DetectCoAuthored: true})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].CoAuthoredReviews)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 0, metricsResult["reviewer2"].CoAuthoredReviews)
+
+	// Excluding leaves the co-authored PR out of the review metrics
+	metricsResult, errs = metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{ExcludeCoAuthored: true})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].CoAuthoredReviews)
+	assert.Equal(t, 0, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 0, metricsResult["reviewer1"].ApprovalsGiven)
+	assert.Equal(t, 1, metricsResult["reviewer2"].PRsReviewed)
+}
+
+func TestCalculateMetrics_CoAuthorTrailers(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, a commit of contributor1 names reviewer1 as co-author by the noreply email
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetCommits", "owner", "repo", 1, dateFrom, []string(nil)).Return([]*gitclient.RepositoryCommit{
+		{CreatedAt: &dateFrom, Message: "Fix the parser\n\nCo-authored-by: Reviewer One <11+reviewer1@users.noreply.github.com>"},
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{CoAuthorTrailers: true})
+
+	// Assertions, the review of the co-author is left out like a self-review
+	assert.Len(t, errs, 0)
+	assert.NotContains(t, metricsResult, "reviewer1")
+	assert.Equal(t, 1, metricsResult["reviewer2"].PRsReviewed)
+}
+
+func TestCalculateMetrics_ExcludeApps(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, the login of the app doesn't end with [bot]
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), UserType: "User", SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("lint-app"), UserType: gitclient.UserTypeBot, SubmittedAt: &dateTo, State: gitclient.ReviewStateCommented},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{ExcludeApps: true})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.NotContains(t, metricsResult, "lint-app")
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+}
+
+func TestCalculateMetrics_AuthorAssociations(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, reviewer2 contributes from outside the organization
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), AuthorAssociation: gitclient.AuthorAssociationMember, SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), AuthorAssociation: gitclient.AuthorAssociationContributor, SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{AuthorAssociations: []string{gitclient.AuthorAssociationMember}})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.NotContains(t, metricsResult, "reviewer2")
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+}
+
+func TestCalculateMetrics_Excluded(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, an app and an external contributor review next to a member
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), UserType: "User", AuthorAssociation: gitclient.AuthorAssociationMember, SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("lint-app"), UserType: gitclient.UserTypeBot, AuthorAssociation: gitclient.AuthorAssociationNone, SubmittedAt: &dateTo, State: gitclient.ReviewStateCommented},
+		{ID: 3, UserID: 13, UserLogin: github.String("reviewer2"), UserType: "User", AuthorAssociation: gitclient.AuthorAssociationContributor, SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	excluded := make(map[string]string)
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{
+		ExcludeApps:        true,
+		AuthorAssociations: []string{gitclient.AuthorAssociationMember},
+		Excluded:           excluded,
+	})
+
+	// Assertions, the app is left out as an app even though its association doesn't match either
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]string{"lint-app": metrics.ExclusionApp, "reviewer2": metrics.ExclusionAssociation}, excluded)
+	assert.NotContains(t, metricsResult, "lint-app")
+	assert.NotContains(t, metricsResult, "reviewer2")
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+}
+
+func TestCalculateMetrics_EditedAfterSubmitReviews(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	submittedAt := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, review 1 was rewritten days later and review 2 had its typo fixed right away
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &submittedAt},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetReviewEdits", "owner", "repo", 1).Return(map[int64]time.Time{
+		1: submittedAt.Add(4 * 24 * time.Hour),
+		2: submittedAt.Add(5 * time.Minute),
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{EditedAfterSubmit: 72 * time.Hour})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].EditedAfterSubmitReviews)
+	assert.Equal(t, 0, metricsResult["reviewer2"].EditedAfterSubmitReviews)
+}
+
+func TestCalculateMetrics_NitpickRatio(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+	mockComments := []*gitclient.PullRequestComment{
+		{ID: 101, PullRequestReviewID: 1, UserID: 11, Body: "nit: trailing whitespace", CreatedAt: &dateTo},
+		{ID: 102, PullRequestReviewID: 1, UserID: 11, Body: "  Nit: naming", CreatedAt: &dateTo},
+		{ID: 103, PullRequestReviewID: 1, UserID: 11, Body: "This lock is never released on the error path", CreatedAt: &dateTo},
+		{ID: 104, PullRequestReviewID: 1, UserID: 11, Body: "Should we split this into two services?", CreatedAt: &dateTo},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, mockComments)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{NitPrefixes: []string{"nit:"}})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].NitComments)
+	assert.Equal(t, 0.5, metricsResult["reviewer1"].NitpickRatio)
+}
+
+func TestCalculateMetrics_ReviewDateScope(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdBefore := time.Date(2024, 12, 20, 9, 0, 0, 0, time.UTC)
+	createdIn := time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC)
+	submittedIn := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	submittedAfter := time.Date(2025, 2, 3, 10, 0, 0, 0, time.UTC)
+
+	// PR 1 was created before the range but reviewed in it, PR 2 was created in the range but reviewed after it
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdBefore, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdIn, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequestsUpdated", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedIn},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetReviews", "owner", "repo", 2).Return([]*gitclient.PullRequestReview{
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &submittedAfter},
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{Scope: metrics.ScopeReviewDate})
+
+	// Assertions, nothing else is fetched for the PR without reviews in the range
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.NotContains(t, metricsResult, "reviewer2")
+	mockClient.AssertNotCalled(t, "GetComments", "owner", "repo", 2)
+}
+
+func TestCalculateMetrics_LongestReviewStreak(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 5, 9, 0, 0, 0, time.UTC)
+
+	// Reviews on January 6, 7 and 8, then a gap until the 10th
+	var mockPullRequests []*gitclient.PullRequest
+	for i, day := range []int{6, 7, 8, 10} {
+		submittedAt := time.Date(2025, 1, day, 10, 0, 0, 0, time.UTC)
+		mockPullRequests = append(mockPullRequests, &gitclient.PullRequest{Number: i + 1, Title: github.String("PR"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")})
+		setupPullRequestMocks(mockClient, "repo", i+1, []*gitclient.PullRequestReview{
+			{ID: int64(i + 1), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		}, []*gitclient.PullRequestComment{})
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 3, metricsResult["reviewer1"].LongestReviewStreak)
+}
+
+func TestCalculateMetrics_SkipWeekends(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data, created on Friday and reviewed on Monday
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC)
+	submittedAt := time.Date(2025, 1, 13, 10, 0, 0, 0, time.UTC)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{SkipWeekends: true})
+
+	// Assertions, the two weekend days are removed from the three days waited
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 24*time.Hour, metricsResult["reviewer1"].AverageTimeToFirstReview)
+}
+
+func TestCalculateMetrics_RenamedFile(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	committedAt := createdAt.Add(2 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+	// The comment asked to rename the file, which the commit did while changing the commented lines
+	mockComments := []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("util.go"), CreatedAt: &commentedAt, OriginalLine: github.Int(10)},
+	}
+	mockCommits := []*gitclient.RepositoryCommit{
+		{CreatedAt: &committedAt, Files: []*gitclient.RepositoryCommitFile{
+			{Filename: github.String("strings.go"), PreviousFilename: github.String("util.go"), Patch: github.String("@@ -10,3 +10,4 @@")},
+		}},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	mockClient.On("GetReviews", "owner", "repo", 1).Return([]*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt},
+	}, nil)
+	mockClient.On("GetComments", "owner", "repo", 1).Return(mockComments, nil)
+	mockClient.On("GetLineStats", "owner", "repo", 1).Return(&gitclient.LineStats{}, nil)
+	mockClient.On("GetCommits", "owner", "repo", 1, commentedAt, []string{"util.go"}).Return(mockCommits, nil)
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 100.0, metricsResult["reviewer1"].PercentageCommentsLeadingToChanges)
+}
+
+func TestCalculateMetrics_ConcurrentReviews(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	at := func(hour int) *time.Time {
+		t := time.Date(2025, 1, 6, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor2")},
+	}
+
+	// Reviewing PR 1 from 10:00 to 12:00 and PR 2 from 11:00 to 13:00
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: at(12)},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, CreatedAt: at(10)},
+	})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: at(13)},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 2, UserID: 11, CreatedAt: at(11)},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions, 4 hours of reviewing spread over 3 hours
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].MaxConcurrentReviews)
+	assert.InDelta(t, 4.0/3.0, metricsResult["reviewer1"].AverageConcurrentReviews, 0.001)
+}
+
+func TestCalculateMetrics_ContextSwitchIndex(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) *time.Time {
+		t := time.Date(2025, 1, 6, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor2")},
+	}
+
+	// reviewer1 alternates between the PRs every 20 minutes, reviewer2 finishes PR 1 hours before turning to PR 2
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: at(10, 40)},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: at(10, 30)},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, CreatedAt: at(10, 0)},
+		{PullRequestReviewID: 2, UserID: 12, CreatedAt: at(10, 0)},
+	})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: at(11, 0)},
+		{ID: 4, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: at(14, 30)},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 3, UserID: 11, CreatedAt: at(10, 20)},
+		{PullRequestReviewID: 4, UserID: 12, CreatedAt: at(14, 0)},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions, reviewer1 touched 1, 2, 2 and 2 PRs in the hour up to each of their activities
+	assert.Len(t, errs, 0)
+	assert.InDelta(t, 7.0/4.0, metricsResult["reviewer1"].ContextSwitchIndex, 0.001)
+	assert.InDelta(t, 1.0, metricsResult["reviewer2"].ContextSwitchIndex, 0.001)
+
+	// The activity is kept for merging with the results of other repositories
+	merged := metrics.Merge(metricsResult, map[string]*metrics.ContributorMetrics{})
+	assert.InDelta(t, 7.0/4.0, merged["reviewer1"].ContextSwitchIndex, 0.001)
+}
+
+func TestCalculateMetrics_DisagreementRate(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, the reviewers disagree on PR 1 and agree on PR 2
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo, State: gitclient.ReviewStateChangesRequested},
+		{ID: 3, UserID: 13, UserLogin: github.String("reviewer3"), SubmittedAt: &dateTo, State: gitclient.ReviewStateCommented},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 4, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		{ID: 5, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions, reviewer3 only commented and takes no side
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 0.5, metricsResult["reviewer1"].DisagreementRate)
+	assert.Equal(t, 0.5, metricsResult["reviewer2"].DisagreementRate)
+	assert.Equal(t, 0.0, metricsResult["reviewer3"].DisagreementRate)
+	assert.Equal(t, 0.5, metrics.TeamDisagreementRate(metricsResult))
+}
+
+func TestCalculateMetrics_RedactTitles(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 42, Title: github.String("Launch Project Falcon"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 42, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Capture the progress log
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// Call the method
+	_, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{RedactTitles: true})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Contains(t, logs.String(), "PR: #42 ")
+	assert.NotContains(t, logs.String(), "Falcon")
+}
+
+func TestCalculateMetricsIncremental_FetchesOnlyUpdated(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now().Add(time.Hour)
+	createdAt := time.Now().Add(-2 * 24 * time.Hour)
+	submittedAt := time.Now().Add(-24 * time.Hour)
+
+	pr1 := &gitclient.PullRequest{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")}
+	pr2 := &gitclient.PullRequest{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")}
+
+	// Set up mock expectations, the first run lists PR 1 and the second only finds PR 2 updated
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return([]*gitclient.PullRequest{pr1}, nil)
+	mockClient.On("GetPullRequestsUpdated", "owner", "repo", mock.Anything, dateTo).Return([]*gitclient.PullRequest{pr2}, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// First run, with an empty cache
+	cache := &metrics.Cache{}
+	metricsResult, errs := metrics.CalculateMetricsIncremental(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{}, cache)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 0, cache.Hits)
+	assert.False(t, cache.UpdatedAt.IsZero())
+	firstUpdatedAt := cache.UpdatedAt
+
+	// Second run, PR 1 comes from the cache
+	metricsResult, errs = metrics.CalculateMetricsIncremental(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{}, cache)
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 1, cache.Hits)
+	mockClient.AssertNumberOfCalls(t, "GetReviews", 2)
+	mockClient.AssertCalled(t, "GetPullRequestsUpdated", "owner", "repo", firstUpdatedAt, dateTo)
+}
+
+func TestFileReviewerCoverage(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Both reviewers comment on api/, only reviewer1 on web/. reviewer2 approves PR 2 without comments.
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("api/handler.go"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("web/app.js"), CreatedAt: &dateTo},
+		{PullRequestReviewID: 2, UserID: 12, Path: github.String("api/routes.go"), CreatedAt: &dateTo},
+	})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetChangedFiles", "owner", "repo", 1).Return([]string{"api/handler.go", "api/routes.go", "web/app.js"}, nil)
+	mockClient.On("GetChangedFiles", "owner", "repo", 2).Return([]string{"README.md"}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Only the comments count without the changed files
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]int{"owner/repo/api": 2, "owner/repo/web": 1}, metrics.FileReviewerCoverage(metricsResult))
+	mockClient.AssertNotCalled(t, "GetChangedFiles", "owner", "repo", 1)
+
+	// The changed files add the files reviewed without comments
+	metricsResult, errs = metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{ChangedFiles: true})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]int{"owner/repo/api": 2, "owner/repo/web": 2, "owner/repo": 1}, metrics.FileReviewerCoverage(metricsResult))
+
+	// The same directory in another repository stays apart once merged
+	mockClient.On("GetPullRequests", "owner", "other", dateFrom, dateTo).Return(mockPullRequests[:1], nil)
+	setupPullRequestMocks(mockClient, "other", 1, []*gitclient.PullRequestReview{
+		{ID: 4, UserID: 13, UserLogin: github.String("reviewer3"), SubmittedAt: &dateTo},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 4, UserID: 13, Path: github.String("api/handler.go"), CreatedAt: &dateTo},
+	})
+	otherResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "other", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, map[string]int{"owner/repo/api": 2, "owner/repo/web": 2, "owner/repo": 1, "owner/other/api": 1}, metrics.FileReviewerCoverage(metrics.Merge(metricsResult, otherResult)))
+}
+
+func TestCalculateMetrics_ReviewCoverage(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+	line := 12
+	baseLine := 41
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Two hunks of api/handler.go, reviewer1 comments on the first and reviewer2 on the base lines of the second
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo},
+		{ID: 3, UserID: 13, UserLogin: github.String("reviewer3"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{PullRequestReviewID: 1, UserID: 11, Path: github.String("api/handler.go"), OriginalLine: &line, Side: gitclient.SideRight, CreatedAt: &dateTo},
+		{PullRequestReviewID: 2, UserID: 12, Path: github.String("api/handler.go"), OriginalLine: &baseLine, Side: gitclient.SideLeft, CreatedAt: &dateTo},
+	})
+	mockClient.On("GetChangedFilePatches", "owner", "repo", 1).Return([]*gitclient.RepositoryCommitFile{
+		{Filename: github.String("api/handler.go"), Patch: github.String("@@ -10,4 +10,5 @@ func handle() {\n context\n+added\n@@ -40,3 +41,2 @@ func route() {\n-removed\n context")},
+		{Filename: github.String("assets/logo.png")},      // Binary, without a patch
+		{Patch: github.String("@@ -1 +1 @@\n-old\n+new")}, // Without a name to match the comments to
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{ReviewCoverage: true})
+
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 0.5, metricsResult["reviewer1"].ReviewCoverage)
+	assert.Equal(t, 0.5, metricsResult["reviewer2"].ReviewCoverage)
+	assert.Equal(t, 0.0, metricsResult["reviewer3"].ReviewCoverage)
+
+	// Merging keeps the share of the hunks rather than averaging the shares
+	merged := metrics.Merge(metricsResult, map[string]*metrics.ContributorMetrics{})
+	assert.Equal(t, 0.5, merged["reviewer1"].ReviewCoverage)
+	mockClient.AssertNotCalled(t, "GetChangedFiles", "owner", "repo", 1)
+}
+
+func TestCalculateMetrics_FirstResponderCount(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	earlier := createdAt.Add(1 * time.Hour)
+	later := createdAt.Add(3 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// reviewer2 reviews PR 1 before reviewer1, the earlier reply of the author doesn't count. PR 2 has a single reviewer.
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &earlier, State: gitclient.ReviewStateCommented},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &later, State: gitclient.ReviewStateApproved},
+		{ID: 3, UserID: 13, UserLogin: github.String("contributor1"), SubmittedAt: &createdAt, State: gitclient.ReviewStateCommented},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 4, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &earlier, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer2"].FirstResponderCount)
+	assert.Equal(t, 0, metricsResult["reviewer1"].FirstResponderCount)
+}
+
+func TestAverageReviewSpread(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	earlier := createdAt.Add(1 * time.Hour)
+	later := earlier.Add(6 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// The first reviews of PR 1 are six hours apart, a later review of reviewer1 doesn't count. PR 2 has a single reviewer.
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &earlier, State: gitclient.ReviewStateCommented},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &later, State: gitclient.ReviewStateApproved},
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 4, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &later, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 6*time.Hour, metrics.AverageReviewSpread(metricsResult))
+	assert.Equal(t, time.Duration(0), metrics.AverageReviewSpread(map[string]*metrics.ContributorMetrics{}))
+}
+
+func TestWeeklyLoad(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data, Monday January 6 2025 starts the ISO week 2025-W02
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	week2 := createdAt.Add(1 * time.Hour)
+	week3 := time.Date(2025, 1, 13, 10, 0, 0, 0, time.UTC)
+	week4 := time.Date(2025, 1, 26, 23, 0, 0, 0, time.UTC) // Sunday, still W04
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Both reviewers count into the same weeks
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &week2, State: gitclient.ReviewStateCommented},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &week2, State: gitclient.ReviewStateCommented},
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &week3, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &week2},
+		{ID: 2, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &week2},
+		{ID: 3, PullRequestReviewID: 2, UserID: 12, Path: github.String("file.go"), CreatedAt: &week2},
+	})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 4, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &week4, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method, with reviewer2 already in week 5 in their own timezone
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{UserLocations: map[string]*time.Location{"reviewer2": tokyo}})
+
+	// Assertions, the weeks are those of the team timezone
+	assert.Len(t, errs, 0)
+	assert.Equal(t, []metrics.WeeklyActivity{
+		{Week: "2025-W02", Reviews: 2, Comments: 3},
+		{Week: "2025-W03", Reviews: 1, Comments: 0},
+		{Week: "2025-W04", Reviews: 1, Comments: 0},
+	}, metrics.WeeklyLoad(metricsResult))
+	assert.Empty(t, metrics.WeeklyLoad(map[string]*metrics.ContributorMetrics{}))
+}
+
+func TestCalculateMetrics_CommentsBeforeApproval(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	approvedAt := createdAt.Add(3 * time.Hour)
+	laterAt := createdAt.Add(5 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Three comments in the first round, the approval, and a comment after it that doesn't count
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt, State: gitclient.ReviewStateCommented},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &approvedAt, State: gitclient.ReviewStateApproved},
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &laterAt, State: gitclient.ReviewStateCommented},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt},
+		{ID: 2, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt},
+		{ID: 3, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt},
+		{ID: 4, PullRequestReviewID: 3, UserID: 11, Path: github.String("file.go"), CreatedAt: &laterAt},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 3.0, metricsResult["reviewer1"].CommentsBeforeApproval)
+	assert.Equal(t, 4, metricsResult["reviewer1"].TotalComments)
+}
+
+func TestCalculateMetrics_EstimatedDurationCount(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	submittedAt := commentedAt.Add(20 * time.Minute)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Neither review of PR 1 has comments to time it by, the review of PR 2 does
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &commentedAt, State: gitclient.ReviewStateCommented},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 3, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].EstimatedDurationCount)
+	assert.Equal(t, 26*time.Minute, metricsResult["reviewer1"].TotalTimeToCompleteReview)
+}
+
+func TestCalculateMetrics_EstimatedDurationCount_ShortSession(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	commentedAt := createdAt.Add(1 * time.Hour)
+	submittedAt := commentedAt.Add(2 * time.Minute)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// A real 2 minute session, timed at the minimum review duration but measured from its comment
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &commentedAt},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	for _, options := range []metrics.Options{{}, {SessionAcrossReviews: true}} {
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+
+		assert.Len(t, errs, 0)
+		assert.Equal(t, 3*time.Minute, metricsResult["reviewer1"].TotalTimeToCompleteReview)
+		assert.Equal(t, 0, metricsResult["reviewer1"].EstimatedDurationCount)
+	}
+}
+
+func TestCalculateMetrics_ChangesRequestOutcomes(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	requestedAt := createdAt.Add(1 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateMerged},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateMerged},
+	}
+
+	// Both PRs merged without the reviewer approving, only the thread of the first one was resolved
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &requestedAt, State: gitclient.ReviewStateChangesRequested},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 1, UserID: 11, Path: github.String("file.go"), CreatedAt: &requestedAt},
+	})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &requestedAt, State: gitclient.ReviewStateChangesRequested},
+	}, []*gitclient.PullRequestComment{
+		{ID: 2, PullRequestReviewID: 2, UserID: 11, Path: github.String("file.go"), CreatedAt: &requestedAt},
+	})
+	mockClient.On("GetReviewThreads", "owner", "repo", 1).Return([]*gitclient.ReviewThread{{ReviewID: 1, Resolved: true}}, nil)
+	mockClient.On("GetReviewThreads", "owner", "repo", 2).Return([]*gitclient.ReviewThread{{ReviewID: 2, Resolved: false}}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Without the threads, merging over both requests overrides them
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 0, metricsResult["reviewer1"].ChangesRequestedResolved)
+	assert.Equal(t, 2, metricsResult["reviewer1"].ChangesRequestedOverridden)
+	mockClient.AssertNotCalled(t, "GetReviewThreads", "owner", "repo", 1)
+
+	// The resolved thread resolves the first request
+	metricsResult, errs = metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{ThreadResolution: true})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].ChangesRequestedResolved)
+	assert.Equal(t, 1, metricsResult["reviewer1"].ChangesRequestedOverridden)
+}
+
+func TestCalculateMetrics_ChangesRequestResolvedByApproval(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	requestedAt := createdAt.Add(1 * time.Hour)
+	approvedAt := createdAt.Add(3 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateOpen},
+	}
+
+	// The first reviewer approved the PR after requesting changes
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &requestedAt, State: gitclient.ReviewStateChangesRequested},
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &approvedAt, State: gitclient.ReviewStateApproved},
+		{ID: 3, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &requestedAt, State: gitclient.ReviewStateChangesRequested},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// The request of reviewer2 on the open PR is still pending
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].ChangesRequestedResolved)
+	assert.Equal(t, 0, metricsResult["reviewer1"].ChangesRequestedOverridden)
+	assert.Equal(t, 0, metricsResult["reviewer2"].ChangesRequestedResolved)
+	assert.Equal(t, 0, metricsResult["reviewer2"].ChangesRequestedOverridden)
+}
+
+func TestCalculateMetrics_Delegation(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	requestedAt := createdAt.Add(10 * time.Minute)
+	submittedAt := createdAt.Add(2 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// reviewer1 is requested but reviewer2 reviews instead
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &submittedAt, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{
+		{Event: gitclient.TimelineEventReviewRequested, CreatedAt: &requestedAt, ReviewerLogin: github.String("reviewer1")},
+	}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{Delegation: true})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].DelegatedAway)
+	assert.Equal(t, 0, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 1, metricsResult["reviewer2"].DelegatedTo)
+	assert.Equal(t, 0, metricsResult["reviewer2"].DelegatedAway)
+}
+
+func TestCalculateMetrics_RequestToMerge(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	requestedAt := createdAt.Add(1 * time.Hour)
+	rerequestedAt := createdAt.Add(5 * time.Hour)
+	submittedAt := createdAt.Add(6 * time.Hour)
+	mergedAt := createdAt.Add(25 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateMerged, MergedAt: &mergedAt},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1"), MergedState: gitclient.MergedStateOpen},
+	}
+
+	// reviewer1 is requested twice on the merged PR 1, reviewer2 reviews it unrequested
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt, State: gitclient.ReviewStateApproved},
+		{ID: 2, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &submittedAt, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetTimelineEvents", "owner", "repo", 1).Return([]*gitclient.TimelineEvent{
+		{Event: gitclient.TimelineEventReviewRequested, CreatedAt: &requestedAt, ReviewerLogin: github.String("reviewer1")},
+		{Event: gitclient.TimelineEventReviewRequested, CreatedAt: &rerequestedAt, ReviewerLogin: github.String("reviewer1")},
+	}, nil)
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 3, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{RequestToMerge: true})
+
+	// Assertions, from the first request to the merge, the open PR 2 left out
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 24*time.Hour, metricsResult["reviewer1"].AverageRequestToMerge)
+	assert.Equal(t, time.Duration(0), metricsResult["reviewer2"].AverageRequestToMerge)
+	mockClient.AssertNotCalled(t, "GetTimelineEvents", "owner", "repo", 2)
+}
+
+func TestCalculateMetrics_Decayed(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data, PR 1 is reviewed one half-life before the end of the range
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	halfLife := 14 * 24 * time.Hour
+	oldReview := dateTo.Add(-halfLife)
+	oldCreatedAt := oldReview.Add(-4 * time.Hour)
+	recentCreatedAt := dateTo.Add(-1 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &oldCreatedAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &recentCreatedAt, UserLogin: github.String("contributor1")},
+	}
+
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &oldReview, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{DecayHalfLife: halfLife, DecayFrom: dateTo})
+
+	// Assertions, the old review weighs half of the recent one while the raw metrics weigh them the same
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].PRsReviewed)
+	assert.InDelta(t, 1.5, metricsResult["reviewer1"].DecayedPRsReviewed, 1e-9)
+	assert.Equal(t, 150*time.Minute, metricsResult["reviewer1"].AverageTimeToFirstReview)
+	assert.Equal(t, 2*time.Hour, metricsResult["reviewer1"].DecayedAverageTimeToFirstReview)
+}
+
+func TestAddInactive(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+
+	// reviewer2 is on the team but did no reviews
+	metrics.AddInactive(metricsResult, []string{"reviewer1", "reviewer2"})
+
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.Contains(t, metricsResult, "reviewer2")
+	assert.Equal(t, 0, metricsResult["reviewer2"].PRsReviewed)
+	assert.Equal(t, 0, metricsResult["reviewer2"].TotalComments)
+
+	// The zero entries merge like any other
+	merged := metrics.Merge(metricsResult)
+	assert.Equal(t, 0, merged["reviewer2"].PRsReviewed)
+}
+
+func TestCalculateMetrics_SizeBand(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("Small PR"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("Medium PR"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 3, Title: github.String("Large PR"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+	sizes := map[int]*gitclient.LineStats{
+		1: {Additions: 5, Deletions: 5},
+		2: {Additions: 80, Deletions: 20},
+		3: {Additions: 900, Deletions: 100},
+	}
+
+	// Set up mock expectations, only the PR within the band has its comments fetched
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, pr := range mockPullRequests {
+		mockClient.On("GetReviews", "owner", "repo", pr.Number).Return([]*gitclient.PullRequestReview{
+			{ID: int64(pr.Number), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		}, nil)
+		mockClient.On("GetLineStats", "owner", "repo", pr.Number).Return(sizes[pr.Number], nil)
+	}
+	mockClient.On("GetComments", "owner", "repo", 2).Return([]*gitclient.PullRequestComment{}, nil)
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{MinChangedLines: 50, MaxChangedLines: 500})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 100, metricsResult["reviewer1"].TotalLinesReviewed)
+	mockClient.AssertNotCalled(t, "GetComments", "owner", "repo", 1)
+	mockClient.AssertNotCalled(t, "GetComments", "owner", "repo", 3)
+	mockClient.AssertNumberOfCalls(t, "GetLineStats", 3)
+}
+
+func TestCalculateMetrics_MergeQueue(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), HeadRef: github.String("feature")},
+		{Number: 2, Title: github.String("Merge queue PR"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1"), HeadRef: github.String("gh-readonly-queue/main/pr-1-6dcb09b5b57875f334f61aebed695e2e4193db5e")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, prNumber := range []int{1, 2} {
+		setupPullRequestMocks(mockClient, "repo", prNumber, []*gitclient.PullRequestReview{
+			{ID: int64(prNumber), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		}, []*gitclient.PullRequestComment{})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// The merge queue PR is left out by default
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	mockClient.AssertNotCalled(t, "GetReviews", "owner", "repo", 2)
+
+	metricsResult, errs = metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{IncludeMergeQueue: true})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].PRsReviewed)
+}
+
+func TestCalculateMetrics_ExcludePRs(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("Bulk reformat"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateCommented},
+		{ID: 3, UserID: 12, UserLogin: github.String("reviewer2"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+	}, []*gitclient.PullRequestComment{
+		{ID: 1, PullRequestReviewID: 2, UserID: 11, CreatedAt: &dateTo},
+	})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{ExcludePRs: []metrics.PullRequestRef{{Repo: "owner/repo", Number: 2}, {Repo: "owner/other", Number: 1}}})
+	assert.Len(t, errs, 0)
+
+	// The excluded PR is neither fetched nor counted for any of its reviewers, the same number in another repository
+	// leaves PR 1 in
+	assert.Len(t, metricsResult, 1)
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 0, metricsResult["reviewer1"].TotalComments)
+	assert.NotContains(t, metricsResult, "reviewer2")
+	mockClient.AssertNotCalled(t, "GetReviews", "owner", "repo", 2)
+}
+
+func TestMarkLowConfidence(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, prNumber := range []int{1, 2} {
+		setupPullRequestMocks(mockClient, "repo", prNumber, []*gitclient.PullRequestReview{
+			{ID: int64(prNumber), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		}, []*gitclient.PullRequestComment{})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2, metricsResult["reviewer1"].PRsReviewed)
+
+	// 2 PRs reviewed are below the sample size of 5
+	metrics.MarkLowConfidence(metricsResult, 5)
+	assert.True(t, metricsResult["reviewer1"].LowConfidence)
+
+	// And enough for a sample size of 2
+	metrics.MarkLowConfidence(metricsResult, 2)
+	assert.False(t, metricsResult["reviewer1"].LowConfidence)
+}
+
+func TestCalculateMetrics_AdaptiveSessionGap(t *testing.T) {
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	startedAt := createdAt.Add(1 * time.Hour)
+	submittedAt := startedAt.Add(120 * time.Minute)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// The reviewer comments every 40 minutes, more slowly than the fixed gap allows
+	var mockComments []*gitclient.PullRequestComment
+	for i := 0; i <= 3; i++ {
+		commentedAt := startedAt.Add(time.Duration(i) * 40 * time.Minute)
+		mockComments = append(mockComments, &gitclient.PullRequestComment{PullRequestReviewID: 1, UserID: 11, Path: github.String("a.go"), CreatedAt: &commentedAt})
+	}
+
+	run := func(options metrics.Options) *metrics.ContributorMetrics {
+		mockClient := new(MockGitClient)
+		mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+		setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+			{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		}, mockComments)
+		mockClient.On("GetApiRateUsed").Return(10)
+		mockClient.On("GetApiRateRemaining").Return(90)
+
+		metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+		assert.Len(t, errs, 0)
+		return metricsResult["reviewer1"]
+	}
+
+	// Every comment is its own session with the fixed gap, leaving the minimum duration
+	assert.Equal(t, 3*time.Minute, run(metrics.Options{}).AverageTimeToCompleteReview)
+	// The 40 minute cadence becomes the gap, so the review is one session
+	assert.Equal(t, 120*time.Minute, run(metrics.Options{SessionGapPercentile: 90}).AverageTimeToCompleteReview)
+}
+
+func TestCalculateMetrics_AdaptiveSessionGapAcrossPRs(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// On each PR the reviewer comments twice 40 minutes apart and submits 40 minutes later, too few intervals to
+	// derive a gap from one PR alone
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, number := range []int{1, 2} {
+		startedAt := createdAt.Add(time.Duration(number) * 24 * time.Hour)
+		commentedAt := startedAt.Add(40 * time.Minute)
+		submittedAt := startedAt.Add(80 * time.Minute)
+		reviewID := int64(number)
+		setupPullRequestMocks(mockClient, "repo", number, []*gitclient.PullRequestReview{
+			{ID: reviewID, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &submittedAt},
+		}, []*gitclient.PullRequestComment{
+			{PullRequestReviewID: reviewID, UserID: 11, Path: github.String("a.go"), CreatedAt: &startedAt},
+			{PullRequestReviewID: reviewID, UserID: 11, Path: github.String("a.go"), CreatedAt: &commentedAt},
+		})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{SessionGapPercentile: 90})
+
+	// The four intervals over both PRs make the 40 minute cadence the gap, so each review is one session
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 80*time.Minute, metricsResult["reviewer1"].AverageTimeToCompleteReview)
+}
+
+func TestCalculateMetrics_TotalDurations(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC)
+	firstReviewAt := createdAt.Add(1 * time.Hour)
+	secondReviewAt := createdAt.Add(3 * time.Hour)
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("PR 1"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("PR 2"), CreatedAt: &createdAt, UserLogin: github.String("contributor1")},
+	}
+
+	// Set up mock expectations, reviews without comments last the minimum review duration
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	setupPullRequestMocks(mockClient, "repo", 1, []*gitclient.PullRequestReview{
+		{ID: 1, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &firstReviewAt},
+	}, []*gitclient.PullRequestComment{})
+	setupPullRequestMocks(mockClient, "repo", 2, []*gitclient.PullRequestReview{
+		{ID: 2, UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &secondReviewAt},
+	}, []*gitclient.PullRequestComment{})
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, metrics.Options{})
+
+	// Assertions
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 4*time.Hour, metricsResult["reviewer1"].TotalTimeToFirstReview)
+	assert.Equal(t, 2*time.Hour, metricsResult["reviewer1"].AverageTimeToFirstReview)
+	assert.Equal(t, 6*time.Minute, metricsResult["reviewer1"].TotalTimeToCompleteReview)
+	assert.Equal(t, 3*time.Minute, metricsResult["reviewer1"].AverageTimeToCompleteReview)
 }
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	"src/gitclient"
+)
+
+// MetricPlugin computes custom metrics from the data of every pull request, next to the built-in metrics. Every
+// calculation calls ProcessPR once per pull request, one at a time, to accumulate into results by keys of its choosing.
+type MetricPlugin interface {
+	ProcessPR(pr *gitclient.PullRequest, reviews []*gitclient.PullRequestReview, comments []*gitclient.PullRequestComment, commits []*gitclient.RepositoryCommit, results map[string]float64)
+}
+
+// TitlePrefixPlugin is an example MetricPlugin counting the pull requests whose title starts with Prefix, e.g.
+// "[hotfix]", ignoring case, in results[Key].
+type TitlePrefixPlugin struct {
+	Prefix string
+	Key    string
+}
+
+func (p TitlePrefixPlugin) ProcessPR(pr *gitclient.PullRequest, reviews []*gitclient.PullRequestReview, comments []*gitclient.PullRequestComment, commits []*gitclient.RepositoryCommit, results map[string]float64) {
+	if pr.Title != nil && strings.HasPrefix(strings.ToLower(*pr.Title), strings.ToLower(p.Prefix)) {
+		results[p.Key]++
+	}
+}
+
+// Runs the plugins of the options on the pull request
+func runPlugins(data *pullRequestData, options Options) {
+	if len(options.Plugins) == 0 {
+		return
+	}
+
+	reviews := getAllReviews(data.userReviews)
+	for _, plugin := range options.Plugins {
+		plugin.ProcessPR(data.pr, reviews, data.comments, data.commits, options.PluginResults)
+	}
+}
+
+// Returns the reviews of all users in the order they were submitted
+func getAllReviews(userReviews map[string][]*gitclient.PullRequestReview) []*gitclient.PullRequestReview {
+	var reviews []*gitclient.PullRequestReview
+	for _, reviewsOfUser := range userReviews {
+		reviews = append(reviews, reviewsOfUser...)
+	}
+	sort.Slice(reviews, func(i, j int) bool { return reviews[i].SubmittedAt.Before(*reviews[j].SubmittedAt) })
+	return reviews
+}
@@ -0,0 +1,49 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"src/gitclient"
+	"src/metrics"
+
+	"github.com/google/go-github/v50/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateMetrics_Plugins(t *testing.T) {
+	mockClient := new(MockGitClient)
+
+	// Mock data
+	dateFrom := time.Now().Add(-7 * 24 * time.Hour)
+	dateTo := time.Now()
+
+	mockPullRequests := []*gitclient.PullRequest{
+		{Number: 1, Title: github.String("[Hotfix] Restore the login"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 2, Title: github.String("Add the settings page"), CreatedAt: &dateFrom, UserLogin: github.String("contributor1")},
+		{Number: 3, Title: github.String("[hotfix] Fix the crash on start"), CreatedAt: &dateFrom, UserLogin: github.String("contributor2")},
+	}
+
+	// Set up mock expectations
+	mockClient.On("GetPullRequests", "owner", "repo", dateFrom, dateTo).Return(mockPullRequests, nil)
+	for _, pr := range mockPullRequests {
+		setupPullRequestMocks(mockClient, "repo", pr.Number, []*gitclient.PullRequestReview{
+			{ID: int64(pr.Number), UserID: 11, UserLogin: github.String("reviewer1"), SubmittedAt: &dateTo, State: gitclient.ReviewStateApproved},
+		}, []*gitclient.PullRequestComment{})
+	}
+	mockClient.On("GetApiRateUsed").Return(10)
+	mockClient.On("GetApiRateRemaining").Return(90)
+
+	// Call the method
+	options := metrics.Options{
+		Plugins:       []metrics.MetricPlugin{metrics.TitlePrefixPlugin{Prefix: "[hotfix]", Key: "Hotfixes"}},
+		PluginResults: make(map[string]float64),
+	}
+	metricsResult, errs := metrics.CalculateMetrics(context.Background(), mockClient, "owner", "repo", dateFrom, dateTo, options)
+
+	// Assertions, the built-in metrics are calculated alongside
+	assert.Len(t, errs, 0)
+	assert.Equal(t, 2.0, options.PluginResults["Hotfixes"])
+	assert.Equal(t, 3, metricsResult["reviewer1"].PRsReviewed)
+}
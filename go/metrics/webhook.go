@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"sort"
+
+	"src/gitclient"
+)
+
+// ApplyWebhookEvent records the review or review comment of a webhook event in the cache, so that Cache.Metrics
+// reflects it without polling. Deleted comments are removed, every other action replaces the review or comment with
+// the same ID. Pull requests first seen in an event only hold what the payloads carry: the line stats, commits and
+// timeline stay empty until the pull request is fetched, e.g. by CalculateMetricsIncremental.
+func (c *Cache) ApplyWebhookEvent(event *gitclient.WebhookEvent) {
+	if c.PullRequests == nil {
+		c.PullRequests = make(map[int]*CachedPullRequest)
+	}
+
+	cached, exists := c.PullRequests[event.PullRequest.Number]
+	if !exists {
+		cached = &CachedPullRequest{LineStats: &gitclient.LineStats{}}
+		c.PullRequests[event.PullRequest.Number] = cached
+	}
+	cached.Repo = event.Repo
+	cached.PullRequest = event.PullRequest // Title, labels and state as of the event
+
+	if event.Review != nil {
+		reviews := []*gitclient.PullRequestReview{event.Review}
+		for _, review := range cached.Reviews {
+			if review.ID != event.Review.ID {
+				reviews = append(reviews, review)
+			}
+		}
+		sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].SubmittedAt.Before(*reviews[j].SubmittedAt) })
+		cached.Reviews = reviews
+	}
+
+	if event.Comment != nil {
+		var comments []*gitclient.PullRequestComment
+		for _, comment := range cached.Comments {
+			if comment.ID != event.Comment.ID {
+				comments = append(comments, comment)
+			}
+		}
+		if event.Action != "deleted" {
+			comments = append(comments, event.Comment)
+		}
+		cached.Comments = comments
+	}
+}
@@ -0,0 +1,51 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"src/gitclient"
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_ApplyWebhookEvent(t *testing.T) {
+	dateFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	dateTo := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	// A comment and the approval it was submitted with, delivered as they happened
+	comment, err := gitclient.ParseWebhookEvent(gitclient.WebhookEventReviewComment, []byte(`{
+		"action": "created",
+		"comment": {"id": 101, "pull_request_review_id": 7, "user": {"id": 11, "login": "reviewer1"}, "path": "a.go", "body": "Missing error check", "created_at": "2025-01-06T10:00:00Z"},
+		"pull_request": {"number": 3, "title": "PR 3", "state": "open", "user": {"login": "contributor1"}, "created_at": "2025-01-06T08:00:00Z"},
+		"repository": {"full_name": "owner/repo"}
+	}`))
+	assert.NoError(t, err)
+	review, err := gitclient.ParseWebhookEvent(gitclient.WebhookEventReview, []byte(`{
+		"action": "submitted",
+		"review": {"id": 7, "user": {"id": 11, "login": "reviewer1"}, "state": "approved", "submitted_at": "2025-01-06T10:00:00Z"},
+		"pull_request": {"number": 3, "title": "PR 3", "state": "open", "user": {"login": "contributor1"}, "created_at": "2025-01-06T08:00:00Z"},
+		"repository": {"full_name": "owner/repo"}
+	}`))
+	assert.NoError(t, err)
+
+	cache := &metrics.Cache{}
+	cache.ApplyWebhookEvent(comment)
+	cache.ApplyWebhookEvent(review)
+	cache.ApplyWebhookEvent(review) // Redelivered
+
+	metricsResult := cache.Metrics(dateFrom, dateTo, metrics.Options{})
+
+	assert.Equal(t, 1, metricsResult["reviewer1"].PRsReviewed)
+	assert.Equal(t, 1, metricsResult["reviewer1"].ApprovalsGiven)
+	assert.Equal(t, 1, metricsResult["reviewer1"].TotalComments)
+	assert.Equal(t, 2*time.Hour, metricsResult["reviewer1"].AverageTimeToFirstReview)
+
+	// Deleting the comment takes it out again
+	comment.Action = "deleted"
+	cache.ApplyWebhookEvent(comment)
+
+	metricsResult = cache.Metrics(dateFrom, dateTo, metrics.Options{})
+	assert.Equal(t, 0, metricsResult["reviewer1"].TotalComments)
+}
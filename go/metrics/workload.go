@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"path"
+	"sort"
+	"time"
+)
+
+// WorkloadGini measures how evenly the review load is spread across the contributors, as the Gini coefficient
+// of their PRsReviewed. 0 means everyone reviewed the same number of pull requests; values approaching 1 mean
+// the reviews are concentrated on a few contributors. Returns 0 when there is nothing to compare.
+func WorkloadGini(results map[string]*ContributorMetrics) float64 {
+	loads := make([]int, 0, len(results))
+	total := 0
+	for _, userMetrics := range results {
+		loads = append(loads, userMetrics.PRsReviewed)
+		total += userMetrics.PRsReviewed
+	}
+
+	if len(loads) == 0 || total == 0 {
+		return 0
+	}
+
+	// G = 2 * sum(i * x_i) / (n * sum(x_i)) - (n + 1) / n, for x sorted ascending and i starting at 1
+	sort.Ints(loads)
+
+	weighted := 0
+	for i, load := range loads {
+		weighted += (i + 1) * load
+	}
+
+	n := float64(len(loads))
+	return 2*float64(weighted)/(n*float64(total)) - (n+1)/n
+}
+
+// TeamDisagreementRate is the share of the jointly decided pull requests where some reviewers approved and others
+// requested changes. Every pull request counts once per reviewer who decided on it. Returns 0 when there is nothing to
+// compare.
+func TeamDisagreementRate(results map[string]*ContributorMetrics) float64 {
+	decided, disagreed := 0, 0
+	for _, userMetrics := range results {
+		decided += userMetrics.jointlyDecidedPRs
+		disagreed += userMetrics.disagreedPRs
+	}
+
+	if decided == 0 {
+		return 0
+	}
+	return float64(disagreed) / float64(decided)
+}
+
+// TeamSLABreachRate is the share of the reviews of all contributors submitted later than the SLA after the review
+// clock started. Returns 0 without reviews or when the SLA is disabled.
+func TeamSLABreachRate(results map[string]*ContributorMetrics, sla time.Duration) float64 {
+	submitted, withinSLA := 0, 0
+	for _, userMetrics := range results {
+		submitted += userMetrics.reviewsSubmitted
+		withinSLA += userMetrics.reviewsWithinSLA
+	}
+
+	if sla <= 0 || submitted == 0 {
+		return 0
+	}
+	return float64(submitted-withinSLA) / float64(submitted)
+}
+
+// AverageReviewSpread is the time between the earliest and the latest first review of the pull requests reviewed by
+// several reviewers, averaged over those pull requests. Short spreads mean the reviewers pick pull requests up
+// together. Every pull request counts once however many reviewers it had. Returns 0 when there is nothing to compare.
+func AverageReviewSpread(results map[string]*ContributorMetrics) time.Duration {
+	prs := 0.0
+	var spread time.Duration
+	for _, userMetrics := range results {
+		prs += userMetrics.spreadPRs
+		spread += userMetrics.reviewSpread
+	}
+
+	if prs == 0 {
+		return 0
+	}
+	return time.Duration(float64(spread) / prs)
+}
+
+// WeeklyActivity is the review load of the whole team in an ISO week
+type WeeklyActivity struct {
+	Week     string // e.g. 2025-W02
+	Reviews  int    // Reviews submitted in the week
+	Comments int    // Comments of those reviews
+}
+
+// WeeklyLoad sums the reviews and comments of all contributors by the ISO week the reviews were submitted in, in
+// Options.Location rather than the timezone of each reviewer, ordered by week. Weeks without reviews are left out.
+func WeeklyLoad(results map[string]*ContributorMetrics) []WeeklyActivity {
+	total := &ContributorMetrics{}
+	for _, userMetrics := range results {
+		for week, activity := range userMetrics.weeks {
+			total.addWeekActivity(week, activity)
+		}
+	}
+
+	load := make([]WeeklyActivity, 0, len(total.weeks))
+	for week, activity := range total.weeks {
+		load = append(load, WeeklyActivity{Week: week, Reviews: activity.reviews, Comments: activity.comments})
+	}
+	sort.Slice(load, func(i, j int) bool { return load[i].Week < load[j].Week })
+	return load
+}
+
+// FileReviewerCoverage counts the distinct reviewers who engaged with the files of every directory, by commenting on
+// them or, with Options.ChangedFiles, by reviewing pull requests changing them. Directories with a single reviewer
+// concentrate the knowledge of that code on one person. The directories are prefixed with the owner/repo of their
+// repository, e.g. "owner/repo/api", and files at the root of a repository belong to "owner/repo".
+func FileReviewerCoverage(results map[string]*ContributorMetrics) map[string]int {
+	coverage := make(map[string]int)
+	for _, userMetrics := range results {
+		dirs := make(map[string]struct{})
+		for file := range userMetrics.filesReviewed {
+			dirs[path.Dir(file)] = struct{}{}
+		}
+		for dir := range dirs {
+			coverage[dir]++
+		}
+	}
+
+	return coverage
+}
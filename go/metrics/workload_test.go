@@ -0,0 +1,30 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkloadGini(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 1},
+		"reviewer2": {PRsReviewed: 2},
+		"reviewer3": {PRsReviewed: 3},
+		"reviewer4": {PRsReviewed: 4},
+	}
+
+	assert.InDelta(t, 0.25, metrics.WorkloadGini(results), 1e-9)
+}
+
+func TestWorkloadGini_EvenAndEmpty(t *testing.T) {
+	even := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 5},
+		"reviewer2": {PRsReviewed: 5},
+	}
+
+	assert.InDelta(t, 0.0, metrics.WorkloadGini(even), 1e-9)
+	assert.Equal(t, 0.0, metrics.WorkloadGini(map[string]*metrics.ContributorMetrics{}))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// detectRepository infers the owner and repository name from the origin remote of the git checkout in the working directory
+func detectRepository() (string, string, error) {
+	output, err := exec.Command("git", "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the origin remote: %v", err)
+	}
+
+	return parseRemoteURL(strings.TrimSpace(string(output)))
+}
+
+// parseRemoteURL extracts the owner and repository name from a github.com remote URL in SSH
+// (git@github.com:owner/repo.git, ssh://git@github.com/owner/repo.git) or HTTPS (https://github.com/owner/repo.git) form
+func parseRemoteURL(remote string) (string, string, error) {
+	var path string
+
+	if strings.HasPrefix(remote, "git@github.com:") {
+		// scp-like SSH syntax
+		path = strings.TrimPrefix(remote, "git@github.com:")
+	} else {
+		parsed, err := url.Parse(remote)
+		if err != nil || parsed.Hostname() != "github.com" {
+			return "", "", fmt.Errorf("not a github.com remote: %s", remote)
+		}
+		if parsed.Scheme != "https" && parsed.Scheme != "http" && parsed.Scheme != "ssh" {
+			return "", "", fmt.Errorf("unsupported remote scheme: %s", remote)
+		}
+		path = parsed.Path
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("remote URL doesn't point to a repository: %s", remote)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// Characters GitHub allows in the names of accounts and repositories
+var (
+	validOwner    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,38}$`)
+	validRepoName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+)
+
+// parseRepository normalizes a repository given by name, as owner/repo or as a github.com URL, e.g. pasted from the
+// browser. A bare name belongs to the owner, which may be empty otherwise, but has to match when it's not.
+func parseRepository(value string, owner string) (Repository, error) {
+	name := strings.TrimSpace(value)
+	repoOwner := owner
+
+	if strings.Contains(name, "github.com") {
+		remote := name
+		if !strings.Contains(remote, "://") && !strings.HasPrefix(remote, "git@") {
+			remote = "https://" + remote
+		}
+		var err error
+		if repoOwner, name, err = parseRemoteURL(remote); err != nil {
+			return Repository{}, err
+		}
+	} else if before, after, found := strings.Cut(name, "/"); found {
+		repoOwner, name = before, after
+	}
+
+	if owner != "" && !strings.EqualFold(owner, repoOwner) {
+		return Repository{}, fmt.Errorf("repository %s/%s doesn't belong to the owner %s", repoOwner, name, owner)
+	}
+	if repoOwner == "" {
+		return Repository{}, fmt.Errorf("missing the owner of %q, expected owner/repo", value)
+	}
+	if !validOwner.MatchString(repoOwner) {
+		return Repository{}, fmt.Errorf("invalid owner %q, only letters, digits and hyphens are allowed", repoOwner)
+	}
+	if !validRepoName.MatchString(name) || name == "." || name == ".." {
+		return Repository{}, fmt.Errorf("invalid repository name %q, only letters, digits, '.', '-' and '_' are allowed", name)
+	}
+
+	return Repository{Owner: repoOwner, Name: name}, nil
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"time"
+)
+
+// SchemaVersion of the JSON envelope. Bump it whenever fields of the JSON output are added, renamed or removed.
+const SchemaVersion = 17
+
+// Decimal places of the text format unless Options.Precision is set. The JSON format keeps full precision.
+const DefaultPrecision = 2
+
+// Options describes what the reports include and the run they were calculated in
+type Options struct {
+	Fields    []string // Metrics of the text format, all of them when empty
+	Precision *int     // Decimal places of the averages and rates in the text format, DefaultPrecision when nil
+
+	// The run named in the JSON envelope
+	Repo        string // owner/repo, comma-separated when the results span several repositories
+	DateFrom    time.Time
+	DateTo      time.Time
+	GeneratedAt time.Time
+}
+
+// Returns the decimal places of the text format
+func (o Options) precision() int {
+	if o.Precision == nil {
+		return DefaultPrecision
+	}
+	return *o.Precision
+}
+
+// Envelope wraps the contributors of the JSON output, so consumers can tell runs and format versions apart
+type Envelope[T any] struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	GeneratedAt   time.Time `json:"generatedAt"`
+	Repo          string    `json:"repo"`
+	Range         Range     `json:"range"`
+	Contributors  T         `json:"contributors"`
+}
+
+// Range is the date range the pull requests were selected from
+type Range struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Wraps the contributors into the envelope describing the run
+func newEnvelope[T any](options Options, contributors T) Envelope[T] {
+	return Envelope[T]{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   options.GeneratedAt,
+		Repo:          options.Repo,
+		Range:         Range{From: options.DateFrom, To: options.DateTo},
+		Contributors:  contributors,
+	}
+}
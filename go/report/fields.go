@@ -0,0 +1,161 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"src/metrics"
+)
+
+// metricField renders a single line of the text format
+type metricField struct {
+	name  string // Name of the ContributorMetrics field, as selected with --fields
+	label string
+	value func(m *metrics.ContributorMetrics, precision int) string // Renders the decimals with the precision
+}
+
+// Metrics of the text format, in output order
+var metricFields = []metricField{
+	{"PRsReviewed", "PRs Reviewed", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%d", m.PRsReviewed) }},
+	{"AverageCommentsPerReview", "Average Comments per Review", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.AverageCommentsPerReview)
+	}},
+	{"AverageTimeToCompleteReview", "Average Time to Complete Review", func(m *metrics.ContributorMetrics, precision int) string {
+		return m.AverageTimeToCompleteReview.String()
+	}},
+	{"AverageTimeToFirstReview", "Average Time to First Review", func(m *metrics.ContributorMetrics, precision int) string { return m.AverageTimeToFirstReview.String() }},
+	{"TotalComments", "Total Comments", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%d", m.TotalComments) }},
+	{"PercentageCommentsLeadingToChanges", "Percentage of Comments Leading to Changes", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f%%", precision, m.PercentageCommentsLeadingToChanges)
+	}},
+	{"ReviewsPerActiveDay", "Reviews per Active Day", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.ReviewsPerActiveDay)
+	}},
+	{"ApprovalsGiven", "Approvals Given", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%d", m.ApprovalsGiven) }},
+	{"ApprovalRate", "Approval Rate", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.ApprovalRate)
+	}},
+	{"InstantApprovals", "Instant Approvals", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.InstantApprovals)
+	}},
+	{"TotalLinesReviewed", "Total Lines Reviewed", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.TotalLinesReviewed)
+	}},
+	{"CommentDensity", "Comment Density", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision+2, m.CommentDensity)
+	}},
+	{"DistinctFilesCommented", "Distinct Files Commented", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.DistinctFilesCommented)
+	}},
+	{"FirstReviewDate", "First Review Date", func(m *metrics.ContributorMetrics, precision int) string {
+		return m.FirstReviewDate.Format("2006-01-02")
+	}},
+	{"DaysActiveInRange", "Days Active in Range", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.DaysActiveInRange)
+	}},
+	{"CrossTeamReviewShare", "Cross-Team Review Share", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.CrossTeamReviewShare)
+	}},
+	{"AcknowledgedComments", "Acknowledged Comments", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.AcknowledgedComments)
+	}},
+	{"ReviewsByHour", "Reviews by Hour", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%v", m.ReviewsByHour) }},
+	{"CommentCategories", "Comment Categories", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%v", m.CommentCategories)
+	}},
+	{"CoAuthoredReviews", "Co-Authored Reviews", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.CoAuthoredReviews)
+	}},
+	{"EditedAfterSubmitReviews", "Edited After Submit Reviews", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.EditedAfterSubmitReviews)
+	}},
+	{"NitComments", "Nit Comments", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%d", m.NitComments) }},
+	{"NitpickRatio", "Nitpick Ratio", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.NitpickRatio)
+	}},
+	{"LongestReviewStreak", "Longest Review Streak", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.LongestReviewStreak)
+	}},
+	{"MaxConcurrentReviews", "Max Concurrent Reviews", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.MaxConcurrentReviews)
+	}},
+	{"AverageConcurrentReviews", "Average Concurrent Reviews", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.AverageConcurrentReviews)
+	}},
+	{"DisagreementRate", "Disagreement Rate", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.DisagreementRate)
+	}},
+	{"FirstResponderCount", "First Responder Count", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.FirstResponderCount)
+	}},
+	{"TotalTimeToFirstReview", "Total Time to First Review", func(m *metrics.ContributorMetrics, precision int) string { return m.TotalTimeToFirstReview.String() }},
+	{"TotalTimeToCompleteReview", "Total Time to Complete Review", func(m *metrics.ContributorMetrics, precision int) string { return m.TotalTimeToCompleteReview.String() }},
+	{"DelegatedAway", "Delegated Away", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%d", m.DelegatedAway) }},
+	{"DelegatedTo", "Delegated To", func(m *metrics.ContributorMetrics, precision int) string { return fmt.Sprintf("%d", m.DelegatedTo) }},
+	{"CommentsBeforeApproval", "Comments Before Approval", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.CommentsBeforeApproval)
+	}},
+	{"EstimatedDurationCount", "Estimated Duration Count", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.EstimatedDurationCount)
+	}},
+	{"ChangesRequestedResolved", "Changes Requested Resolved", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.ChangesRequestedResolved)
+	}},
+	{"ChangesRequestedOverridden", "Changes Requested Overridden", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%d", m.ChangesRequestedOverridden)
+	}},
+	{"ReviewCoverage", "Review Coverage", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.ReviewCoverage)
+	}},
+	{"AverageRequestToMerge", "Average Request to Merge", func(m *metrics.ContributorMetrics, precision int) string {
+		return m.AverageRequestToMerge.String()
+	}},
+	{"ContextSwitchIndex", "Context Switch Index", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.ContextSwitchIndex)
+	}},
+	{"DecayedPRsReviewed", "Decayed PRs Reviewed", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.DecayedPRsReviewed)
+	}},
+	{"DecayedTotalComments", "Decayed Total Comments", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.DecayedTotalComments)
+	}},
+	{"DecayedAverageTimeToFirstReview", "Decayed Average Time to First Review", func(m *metrics.ContributorMetrics, precision int) string {
+		return m.DecayedAverageTimeToFirstReview.String()
+	}},
+	{"SLAComplianceRate", "SLA Compliance Rate", func(m *metrics.ContributorMetrics, precision int) string {
+		return fmt.Sprintf("%.*f", precision, m.SLAComplianceRate)
+	}},
+}
+
+// FieldNames returns the names of the metrics that can be selected for the text format, in output order
+func FieldNames() []string {
+	names := make([]string, len(metricFields))
+	for i, field := range metricFields {
+		names[i] = field.name
+	}
+	return names
+}
+
+// ParseFields splits the comma-separated metric names and checks each of them against FieldNames.
+// An empty list selects all metrics.
+func ParseFields(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool, len(metricFields))
+	for _, field := range metricFields {
+		known[field.name] = true
+	}
+
+	var fields []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", name, strings.Join(FieldNames(), ", "))
+		}
+		fields = append(fields, name)
+	}
+
+	return fields, nil
+}
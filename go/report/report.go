@@ -0,0 +1,523 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"src/metrics"
+)
+
+// Supported output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml" // The JSON envelope with durations in seconds
+)
+
+// Name of the file holding the merged results when writing to a directory
+const allReportName = "all"
+
+// RepoReport holds the calculated metrics of a single repository
+type RepoReport struct {
+	Owner   string
+	Repo    string
+	Metrics map[string]*metrics.ContributorMetrics
+}
+
+// IsSupportedFormat reports whether the format can be written
+func IsSupportedFormat(format string) bool {
+	return format == FormatText || isDocument(format)
+}
+
+// Reports whether the format encodes the results as a single document, JSON or YAML, rather than text
+func isDocument(format string) bool {
+	return format == FormatJSON || format == FormatYAML
+}
+
+// Write renders the metrics in the given format. The text format only includes the metrics named in
+// options.Fields, the JSON and YAML formats wrap the metrics into an Envelope.
+func Write(w io.Writer, format string, results map[string]*metrics.ContributorMetrics, options Options) error {
+	switch format {
+	case FormatText:
+		return writeText(w, results, options)
+	case FormatJSON, FormatYAML:
+		return writeDocument(w, format, newEnvelope(options, results))
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
+
+// ReadJSON reads results previously written by Write in the JSON format, e.g. to use them as a baseline
+func ReadJSON(path string) (map[string]*metrics.ContributorMetrics, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var envelope Envelope[map[string]*metrics.ContributorMetrics]
+	if err := json.NewDecoder(file).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to read results from %s: %v", path, err)
+	}
+	if envelope.SchemaVersion == 0 || envelope.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("failed to read results from %s: unsupported schema version %d", path, envelope.SchemaVersion)
+	}
+
+	return envelope.Contributors, nil
+}
+
+// WriteDelta renders the differences to a baseline as calculated by metrics.Delta, with explicit signs in the text format
+func WriteDelta(w io.Writer, format string, deltas map[string]*metrics.ContributorMetrics, options Options) error {
+	if isDocument(format) {
+		return writeDocument(w, format, deltas)
+	}
+
+	precision := options.precision()
+
+	for _, contributor := range sortedKeys(deltas) {
+		m := deltas[contributor]
+		if _, err := fmt.Fprintf(w, "Contributor: %s\n"+
+			"PRs Reviewed: %+d\n"+
+			"Average Comments per Review: %+.*f\n"+
+			"Average Time to Complete Review: %s\n"+
+			"Average Time to First Review: %s\n"+
+			"Total Comments: %+d\n"+
+			"Percentage of Comments Leading to Changes: %+.*f%%\n"+
+			"Reviews per Active Day: %+.*f\n"+
+			"Approvals Given: %+d\n"+
+			"Approval Rate: %+.*f\n"+
+			"Instant Approvals: %+d\n"+
+			"Total Lines Reviewed: %+d\n"+
+			"Comment Density: %+.*f\n"+
+			"Distinct Files Commented: %+d\n"+
+			"Days Active in Range: %+d\n"+
+			"Cross-Team Review Share: %+.*f\n"+
+			"Acknowledged Comments: %+d\n"+
+			"Co-Authored Reviews: %+d\n"+
+			"Edited After Submit Reviews: %+d\n"+
+			"Nit Comments: %+d\n"+
+			"Nitpick Ratio: %+.*f\n"+
+			"Longest Review Streak: %+d\n"+
+			"Max Concurrent Reviews: %+d\n"+
+			"Average Concurrent Reviews: %+.*f\n"+
+			"Disagreement Rate: %+.*f\n"+
+			"First Responder Count: %+d\n"+
+			"Total Time to First Review: %s\n"+
+			"Total Time to Complete Review: %s\n"+
+			"Delegated Away: %+d\n"+
+			"Delegated To: %+d\n"+
+			"Comments Before Approval: %+.*f\n"+
+			"Estimated Duration Count: %+d\n"+
+			"Changes Requested Resolved: %+d\n"+
+			"Changes Requested Overridden: %+d\n"+
+			"Review Coverage: %+.*f\n"+
+			"Average Request to Merge: %s\n"+
+			"Context Switch Index: %+.*f\n"+
+			"Decayed PRs Reviewed: %+.*f\n"+
+			"Decayed Total Comments: %+.*f\n"+
+			"Decayed Average Time to First Review: %s\n"+
+			"SLA Compliance Rate: %+.*f\n\n",
+			contributor,
+			m.PRsReviewed,
+			precision, m.AverageCommentsPerReview,
+			formatSignedDuration(m.AverageTimeToCompleteReview),
+			formatSignedDuration(m.AverageTimeToFirstReview),
+			m.TotalComments,
+			precision, m.PercentageCommentsLeadingToChanges,
+			precision, m.ReviewsPerActiveDay,
+			m.ApprovalsGiven,
+			precision, m.ApprovalRate,
+			m.InstantApprovals,
+			m.TotalLinesReviewed,
+			precision+2, m.CommentDensity,
+			m.DistinctFilesCommented,
+			m.DaysActiveInRange,
+			precision, m.CrossTeamReviewShare,
+			m.AcknowledgedComments,
+			m.CoAuthoredReviews,
+			m.EditedAfterSubmitReviews,
+			m.NitComments,
+			precision, m.NitpickRatio,
+			m.LongestReviewStreak,
+			m.MaxConcurrentReviews,
+			precision, m.AverageConcurrentReviews,
+			precision, m.DisagreementRate,
+			m.FirstResponderCount,
+			formatSignedDuration(m.TotalTimeToFirstReview),
+			formatSignedDuration(m.TotalTimeToCompleteReview),
+			m.DelegatedAway,
+			m.DelegatedTo,
+			precision, m.CommentsBeforeApproval,
+			m.EstimatedDurationCount,
+			m.ChangesRequestedResolved,
+			m.ChangesRequestedOverridden,
+			precision, m.ReviewCoverage,
+			formatSignedDuration(m.AverageRequestToMerge),
+			precision, m.ContextSwitchIndex,
+			precision, m.DecayedPRsReviewed,
+			precision, m.DecayedTotalComments,
+			formatSignedDuration(m.DecayedAverageTimeToFirstReview),
+			precision, m.SLAComplianceRate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Formats the duration with a leading + when it is not negative
+func formatSignedDuration(d time.Duration) string {
+	if d < 0 {
+		return d.String()
+	}
+	return "+" + d.String()
+}
+
+// WriteAuthors renders the metrics of the pull request authors, ordered by author
+func WriteAuthors(w io.Writer, format string, results map[string]*metrics.AuthorMetrics) error {
+	if isDocument(format) {
+		return writeDocument(w, format, results)
+	}
+
+	for _, author := range sortedKeys(results) {
+		m := results[author]
+		if _, err := fmt.Fprintf(w, "Author: %s\n"+
+			"PRs Authored: %d\n"+
+			"Comments Responded: %d\n"+
+			"Average Author Response Time: %v\n"+
+			"Average Time to First Commit: %v\n\n",
+			author,
+			m.PRsAuthored,
+			m.CommentsResponded,
+			m.AverageAuthorResponseTime,
+			m.AverageTimeToFirstCommit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteGrouped renders the metrics of every group, ordered by group name
+func WriteGrouped(w io.Writer, format string, results map[string]map[string]*metrics.ContributorMetrics, options Options) error {
+	if isDocument(format) {
+		return writeDocument(w, format, newEnvelope(options, results))
+	}
+
+	for _, group := range sortedKeys(results) {
+		if _, err := fmt.Fprintf(w, "=== %s ===\n\n", group); err != nil {
+			return err
+		}
+		if err := Write(w, format, results[group], options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteLeaderboard renders the ranked contributors
+func WriteLeaderboard(w io.Writer, format string, entries []metrics.LeaderboardEntry, options Options) error {
+	if isDocument(format) {
+		return writeDocument(w, format, entries)
+	}
+
+	precision := options.precision()
+	for i, entry := range entries {
+		if _, err := fmt.Fprintf(w, "%d. %s (score %.*f, PRs reviewed %d, comment density %.*f, SLA compliance %.*f)\n",
+			i+1, entry.Contributor, precision+1, entry.ReviewerScore, entry.Metrics.PRsReviewed, precision+2, entry.Metrics.CommentDensity, precision, entry.Metrics.SLAComplianceRate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Efficiency summarizes the API usage of a run
+type Efficiency struct {
+	APICalls           int  // API calls of the whole run, including listing the pull requests
+	PullRequests       int  // Pull requests fetched from the API
+	CachedPullRequests int  // Pull requests taken from the cache without fetching them
+	CacheEnabled       bool // Whether the run used a cache, which reports the hit rate
+
+	APICallsByOperation map[string]int // API calls by client operation, e.g. reviews or comments, omitted when empty
+}
+
+// Returns the API calls per analyzed pull request, zero when there was none
+func (e Efficiency) CallsPerPullRequest() float64 {
+	total := e.PullRequests + e.CachedPullRequests
+	if total == 0 {
+		return 0
+	}
+	return float64(e.APICalls) / float64(total)
+}
+
+// Returns the share of the analyzed pull requests taken from the cache, zero when there was none
+func (e Efficiency) CacheHitRate() float64 {
+	total := e.PullRequests + e.CachedPullRequests
+	if total == 0 {
+		return 0
+	}
+	return float64(e.CachedPullRequests) / float64(total)
+}
+
+// WriteEfficiency renders the API usage as a footer of the text report. The JSON and YAML formats write nothing,
+// keeping the output a single document.
+func WriteEfficiency(w io.Writer, format string, e Efficiency, options Options) error {
+	if isDocument(format) {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "API Efficiency\nAPI Calls: %d\nAPI Calls per PR: %.*f\n", e.APICalls, options.precision(), e.CallsPerPullRequest()); err != nil {
+		return err
+	}
+	if e.CacheEnabled {
+		if _, err := fmt.Fprintf(w, "Cache Hit Rate: %.*f\n", options.precision(), e.CacheHitRate()); err != nil {
+			return err
+		}
+	}
+	if len(e.APICallsByOperation) > 0 {
+		// The operations with the most calls first, where a slow run spends its time
+		operations := sortedKeys(e.APICallsByOperation)
+		sort.SliceStable(operations, func(i, j int) bool {
+			return e.APICallsByOperation[operations[i]] > e.APICallsByOperation[operations[j]]
+		})
+
+		parts := make([]string, len(operations))
+		for i, operation := range operations {
+			parts[i] = fmt.Sprintf("%s %d", operation, e.APICallsByOperation[operation])
+		}
+		if _, err := fmt.Fprintf(w, "API Calls by Operation: %s\n", strings.Join(parts, ", ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteExcluded renders the reviewers left out of the results by login, with the reason they were left out for.
+// The document formats write nothing, leaving their output the results alone.
+func WriteExcluded(w io.Writer, format string, excluded map[string]string) error {
+	if isDocument(format) {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(w, "Excluded Reviewers\n"); err != nil {
+		return err
+	}
+	for _, login := range sortedKeys(excluded) {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", login, excluded[login]); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "\n"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WriteByContributor renders the metrics of every contributor split by period (e.g. month), ordered by contributor and period
+func WriteByContributor(w io.Writer, format string, results map[string]map[string]*metrics.ContributorMetrics, options Options) error {
+	if isDocument(format) {
+		return writeDocument(w, format, newEnvelope(options, results))
+	}
+
+	for _, contributor := range sortedKeys(results) {
+		if _, err := fmt.Fprintf(w, "Contributor: %s\n\n", contributor); err != nil {
+			return err
+		}
+
+		for _, period := range sortedKeys(results[contributor]) {
+			if _, err := fmt.Fprintf(w, "Period: %s\n", period); err != nil {
+				return err
+			}
+			if err := writeMetricsText(w, results[contributor][period], options); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteDir writes one file per repository named <owner>_<repo>.<ext> into dir. When includeAll is set,
+// the metrics of all repositories are merged into an additional "all" file. The JSON envelope of every repository
+// file names that repository.
+func WriteDir(dir string, format string, reports []RepoReport, includeAll bool, options Options) error {
+	if !IsSupportedFormat(format) {
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	for _, report := range reports {
+		repoOptions := options
+		repoOptions.Repo = report.Owner + "/" + report.Repo
+		if err := writeFile(filepath.Join(dir, fileName(report.Owner+"_"+report.Repo, format)), format, report.Metrics, repoOptions); err != nil {
+			return err
+		}
+	}
+
+	if includeAll {
+		all := make([]map[string]*metrics.ContributorMetrics, len(reports))
+		for i, report := range reports {
+			all[i] = report.Metrics
+		}
+
+		if err := writeFile(filepath.Join(dir, fileName(allReportName, format)), format, metrics.Merge(all...), options); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Creates the file and writes the metrics into it
+func writeFile(path string, format string, results map[string]*metrics.ContributorMetrics, options Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %v", err)
+	}
+	defer file.Close()
+
+	return Write(file, format, results, options)
+}
+
+// Returns the file name with the extension matching the format
+func fileName(name string, format string) string {
+	extension := "txt"
+	if isDocument(format) {
+		extension = format
+	}
+
+	return name + "." + extension
+}
+
+func writeText(w io.Writer, results map[string]*metrics.ContributorMetrics, options Options) error {
+	for _, contributor := range sortedKeys(results) {
+		marker := ""
+		if results[contributor].LowConfidence {
+			marker = " (low confidence, few PRs reviewed)"
+		}
+		if _, err := fmt.Fprintf(w, "Contributor: %s%s\n", contributor, marker); err != nil {
+			return err
+		}
+		if err := writeMetricsText(w, results[contributor], options); err != nil {
+			return err
+		}
+	}
+
+	// Team-level numbers derived from all contributors
+	if len(results) > 0 {
+		if _, err := fmt.Fprintf(w, "Summary\nReview Load Gini Coefficient: %.*f\nDisagreement Rate: %.*f\nAverage Review Spread: %s\n\n",
+			options.precision()+1, metrics.WorkloadGini(results), options.precision(), metrics.TeamDisagreementRate(results), metrics.AverageReviewSpread(results)); err != nil {
+			return err
+		}
+		if err := writeWeeklyLoadText(w, metrics.WeeklyLoad(results)); err != nil {
+			return err
+		}
+		if err := writeCoverageText(w, metrics.FileReviewerCoverage(results)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Writes the reviews and comments of the team in every week, in order
+func writeWeeklyLoadText(w io.Writer, load []metrics.WeeklyActivity) error {
+	if len(load) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprint(w, "Weekly Review Load\n"); err != nil {
+		return err
+	}
+	for _, week := range load {
+		if _, err := fmt.Fprintf(w, "%s: %d reviews, %d comments\n", week.Week, week.Reviews, week.Comments); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+// Writes the distinct reviewers of every directory, the least covered ones first
+func writeCoverageText(w io.Writer, coverage map[string]int) error {
+	if len(coverage) == 0 {
+		return nil
+	}
+
+	dirs := sortedKeys(coverage)
+	sort.SliceStable(dirs, func(i, j int) bool { return coverage[dirs[i]] < coverage[dirs[j]] })
+
+	if _, err := fmt.Fprint(w, "File Reviewer Coverage\n"); err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if _, err := fmt.Fprintf(w, "%s: %d\n", dir, coverage[dir]); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+// Writes the metrics selected in options.Fields, or all of them when it is empty
+func writeMetricsText(w io.Writer, m *metrics.ContributorMetrics, options Options) error {
+	fields := options.Fields
+	precision := options.precision()
+	selected := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		selected[name] = true
+	}
+
+	for _, field := range metricFields {
+		if len(fields) > 0 && !selected[field.name] {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", field.label, field.value(m, precision)); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprint(w, "\n")
+	return err
+}
+
+// Writes the value as a document of the JSON or YAML format
+func writeDocument(w io.Writer, format string, value any) error {
+	if format == FormatYAML {
+		return writeYAML(w, value)
+	}
+	return writeJSON(w, value)
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// Returns the keys of the map in ascending order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,314 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite_Text(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer2": {PRsReviewed: 1},
+		"reviewer1": {PRsReviewed: 3, TotalComments: 6, AverageCommentsPerReview: 2},
+	}
+
+	var buf bytes.Buffer
+	err := Write(&buf, FormatText, results, Options{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Contributor: reviewer1\nPRs Reviewed: 3\nAverage Comments per Review: 2.00\n")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("reviewer1")), bytes.Index(buf.Bytes(), []byte("reviewer2")))
+	assert.Contains(t, buf.String(), "Summary\nReview Load Gini Coefficient: 0.250\n")
+}
+
+func TestWriteCoverageText_LeastCoveredFirst(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeCoverageText(&buf, map[string]int{"api": 3, "web": 1, ".": 2, "docs": 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "File Reviewer Coverage\ndocs: 1\nweb: 1\n.: 2\napi: 3\n\n", buf.String())
+}
+
+func TestWrite_SelectedFields(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 3, TotalComments: 6, AverageCommentsPerReview: 2},
+	}
+	fields, err := ParseFields("TotalComments, PRsReviewed")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = Write(&buf, FormatText, results, Options{Fields: fields})
+
+	// Selected metrics keep the output order
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Contributor: reviewer1\nPRs Reviewed: 3\nTotal Comments: 6\n\n")
+	assert.NotContains(t, buf.String(), "Average Comments per Review")
+	assert.NotContains(t, buf.String(), "SLA Compliance Rate")
+}
+
+func TestWrite_Precision(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 3, AverageCommentsPerReview: 2.3456, PercentageCommentsLeadingToChanges: 41.6667, CommentDensity: 0.012345},
+	}
+	fields, err := ParseFields("AverageCommentsPerReview,PercentageCommentsLeadingToChanges,CommentDensity")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	precision := 0
+	err = Write(&buf, FormatText, results, Options{Fields: fields, Precision: &precision})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Average Comments per Review: 2\nPercentage of Comments Leading to Changes: 42%\nComment Density: 0.01\n")
+
+	buf.Reset()
+	precision = 3
+	err = Write(&buf, FormatText, results, Options{Fields: fields, Precision: &precision})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Average Comments per Review: 2.346\nPercentage of Comments Leading to Changes: 41.667%\nComment Density: 0.01235\n")
+}
+
+func TestParseFields_UnknownField(t *testing.T) {
+	_, err := ParseFields("PRsReviewed,Karma")
+	assert.ErrorContains(t, err, `unknown field "Karma"`)
+}
+
+func TestWrite_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, "xml", map[string]*metrics.ContributorMetrics{}, Options{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported output format")
+}
+
+func TestWriteDir_OneFilePerRepo(t *testing.T) {
+	dir := t.TempDir()
+	reports := []RepoReport{
+		{Owner: "owner", Repo: "repoA", Metrics: map[string]*metrics.ContributorMetrics{"reviewer1": {PRsReviewed: 2}}},
+		{Owner: "owner", Repo: "repoB", Metrics: map[string]*metrics.ContributorMetrics{"reviewer2": {PRsReviewed: 1}}},
+	}
+
+	err := WriteDir(dir, FormatJSON, reports, false, Options{})
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	repoA, err := os.ReadFile(filepath.Join(dir, "owner_repoA.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(repoA), `"reviewer1"`)
+	assert.NotContains(t, string(repoA), `"reviewer2"`)
+
+	repoB, err := os.ReadFile(filepath.Join(dir, "owner_repoB.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(repoB), `"reviewer2"`)
+}
+
+func TestWriteDir_IncludeAll(t *testing.T) {
+	dir := t.TempDir()
+	reports := []RepoReport{
+		{Owner: "owner", Repo: "repoA", Metrics: map[string]*metrics.ContributorMetrics{"reviewer1": {PRsReviewed: 2}}},
+		{Owner: "owner", Repo: "repoB", Metrics: map[string]*metrics.ContributorMetrics{"reviewer2": {PRsReviewed: 1}}},
+	}
+
+	err := WriteDir(dir, FormatText, reports, true, Options{})
+	assert.NoError(t, err)
+
+	all, err := os.ReadFile(filepath.Join(dir, "all.txt"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(all), "Contributor: reviewer1\nPRs Reviewed: 2\n")
+	assert.Contains(t, string(all), "Contributor: reviewer2\nPRs Reviewed: 1\n")
+}
+
+func TestWriteByContributor_Text(t *testing.T) {
+	results := map[string]map[string]*metrics.ContributorMetrics{
+		"reviewer1": {
+			"2025-02": {PRsReviewed: 2},
+			"2025-01": {PRsReviewed: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := WriteByContributor(&buf, FormatText, results, Options{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Contributor: reviewer1\n\nPeriod: 2025-01\nPRs Reviewed: 1\n")
+	assert.Less(t, bytes.Index(buf.Bytes(), []byte("2025-01")), bytes.Index(buf.Bytes(), []byte("2025-02")))
+}
+
+func TestWriteLeaderboard_Text(t *testing.T) {
+	entries := []metrics.LeaderboardEntry{
+		{Contributor: "reviewer2", ReviewerScore: 0.75, Metrics: &metrics.ContributorMetrics{PRsReviewed: 5}},
+		{Contributor: "reviewer1", ReviewerScore: 0.6125, Metrics: &metrics.ContributorMetrics{PRsReviewed: 10}},
+	}
+
+	var buf bytes.Buffer
+	err := WriteLeaderboard(&buf, FormatText, entries, Options{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "1. reviewer2 (score 0.750, PRs reviewed 5,")
+	assert.Contains(t, buf.String(), "2. reviewer1 (score 0.613, PRs reviewed 10,")
+
+	buf.Reset()
+	precision := 0
+	err = WriteLeaderboard(&buf, FormatText, entries, Options{Precision: &precision})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "1. reviewer2 (score 0.8, PRs reviewed 5, comment density 0.00, SLA compliance 0)\n")
+}
+
+func TestWriteEfficiency_Text(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteEfficiency(&buf, FormatText, Efficiency{APICalls: 31, PullRequests: 3, CachedPullRequests: 7, CacheEnabled: true}, Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "API Efficiency\nAPI Calls: 31\nAPI Calls per PR: 3.10\nCache Hit Rate: 0.70\n", buf.String())
+}
+
+func TestWriteEfficiency_ByOperation(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteEfficiency(&buf, FormatText, Efficiency{APICalls: 31, PullRequests: 10, APICallsByOperation: map[string]int{
+		"comments": 10, "pull requests": 1, "reviews": 10, "commit details": 10,
+	}}, Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "API Efficiency\nAPI Calls: 31\nAPI Calls per PR: 3.10\nAPI Calls by Operation: comments 10, commit details 10, reviews 10, pull requests 1\n", buf.String())
+}
+
+func TestWriteExcluded_Text(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteExcluded(&buf, FormatText, map[string]string{"renovate[bot]": metrics.ExclusionApp, "outsider": metrics.ExclusionAssociation, "newcomer": metrics.ExclusionBelowMinSample})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Excluded Reviewers\nnewcomer: below-min-sample\noutsider: association\nrenovate[bot]: app\n\n", buf.String())
+}
+
+func TestWriteEfficiency_JSONWritesNothing(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteEfficiency(&buf, FormatJSON, Efficiency{APICalls: 31, PullRequests: 3}, Options{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteAuthors_Text(t *testing.T) {
+	results := map[string]*metrics.AuthorMetrics{
+		"contributor1": {PRsAuthored: 2, CommentsResponded: 3, AverageAuthorResponseTime: time.Hour, AverageTimeToFirstCommit: 30 * time.Minute},
+	}
+
+	var buf bytes.Buffer
+	err := WriteAuthors(&buf, FormatText, results)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Author: contributor1\nPRs Authored: 2\nComments Responded: 3\nAverage Author Response Time: 1h0m0s\nAverage Time to First Commit: 30m0s\n\n", buf.String())
+}
+
+func TestReadJSON_Baseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	assert.NoError(t, Write(file, FormatJSON, map[string]*metrics.ContributorMetrics{"reviewer1": {PRsReviewed: 3, AverageTimeToFirstReview: time.Hour}}, Options{}))
+	file.Close()
+
+	results, err := ReadJSON(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, results["reviewer1"].PRsReviewed)
+	assert.Equal(t, time.Hour, results["reviewer1"].AverageTimeToFirstReview)
+}
+
+func TestWriteDelta_Text(t *testing.T) {
+	deltas := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: -4, TotalComments: 2, AverageTimeToFirstReview: time.Hour},
+	}
+
+	var buf bytes.Buffer
+	err := WriteDelta(&buf, FormatText, deltas, Options{})
+
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Contributor: reviewer1\nPRs Reviewed: -4\n")
+	assert.Contains(t, buf.String(), "Average Time to First Review: +1h0m0s\nTotal Comments: +2\n")
+}
+
+func TestWriteDelta_Precision(t *testing.T) {
+	deltas := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {AverageCommentsPerReview: 1.4567, CommentDensity: -0.012345},
+	}
+
+	var buf bytes.Buffer
+	precision := 0
+	err := WriteDelta(&buf, FormatText, deltas, Options{Precision: &precision})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Average Comments per Review: +1\n")
+	assert.Contains(t, buf.String(), "Comment Density: -0.01\n")
+
+	buf.Reset()
+	precision = 3
+	err = WriteDelta(&buf, FormatText, deltas, Options{Precision: &precision})
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Average Comments per Review: +1.457\n")
+	assert.Contains(t, buf.String(), "Comment Density: -0.01235\n")
+}
+
+func TestWrite_JSONIsByteStable(t *testing.T) {
+	// Merging and ranking iterate maps, whose order differs between iterations
+	render := func() []byte {
+		repo1 := map[string]*metrics.ContributorMetrics{
+			"reviewer1": {PRsReviewed: 2, TotalComments: 4},
+			"reviewer2": {PRsReviewed: 2, TotalComments: 4},
+			"reviewer3": {PRsReviewed: 1},
+		}
+		repo2 := map[string]*metrics.ContributorMetrics{
+			"reviewer3": {PRsReviewed: 1, TotalComments: 1},
+			"reviewer4": {PRsReviewed: 3},
+		}
+		merged := metrics.Merge(repo1, repo2)
+
+		var buf bytes.Buffer
+		assert.NoError(t, Write(&buf, FormatJSON, merged, Options{}))
+		assert.NoError(t, WriteLeaderboard(&buf, FormatJSON, metrics.Leaderboard(merged, metrics.DefaultScoreWeights), Options{}))
+		assert.NoError(t, WriteGrouped(&buf, FormatJSON, map[string]map[string]*metrics.ContributorMetrics{"bug": repo1, "feature": repo2}, Options{}))
+		return buf.Bytes()
+	}
+
+	expected := render()
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, render())
+	}
+}
+
+func TestWrite_JSONEnvelope(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{"reviewer1": {PRsReviewed: 3}}
+	options := Options{
+		Repo:        "owner/repo",
+		DateFrom:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+		GeneratedAt: time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, FormatJSON, results, options))
+
+	var envelope map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &envelope))
+	assert.Equal(t, strconv.Itoa(SchemaVersion), string(envelope["schemaVersion"]))
+	assert.JSONEq(t, `"2025-02-01T08:00:00Z"`, string(envelope["generatedAt"]))
+	assert.JSONEq(t, `"owner/repo"`, string(envelope["repo"]))
+	assert.JSONEq(t, `{"from": "2025-01-01T00:00:00Z", "to": "2025-01-31T23:59:59Z"}`, string(envelope["range"]))
+	assert.Contains(t, string(envelope["contributors"]), `"reviewer1": {`)
+}
+
+func TestReadJSON_RejectsBareMap(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"reviewer1": {"PRsReviewed": 3}}`), 0o644))
+
+	_, err := ReadJSON(file)
+
+	assert.ErrorContains(t, err, "unsupported schema version 0")
+}
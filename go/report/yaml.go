@@ -0,0 +1,267 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"src/metrics"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Writes the value as a YAML document with the field names and order of its JSON encoding, except that durations
+// are in seconds rather than nanoseconds
+func writeYAML(w io.Writer, value any) error {
+	node, err := yamlNode(reflect.ValueOf(value))
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// ReadYAML reads results previously written by Write in the YAML format, e.g. to use them as a baseline
+func ReadYAML(path string) (map[string]*metrics.ContributorMetrics, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var envelope Envelope[map[string]*metrics.ContributorMetrics]
+	if err := decodeYAML(file, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to read results from %s: %v", path, err)
+	}
+	if envelope.SchemaVersion == 0 || envelope.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("failed to read results from %s: unsupported schema version %d", path, envelope.SchemaVersion)
+	}
+
+	return envelope.Contributors, nil
+}
+
+// Decodes a YAML document written by writeYAML into the value pointed to
+func decodeYAML(r io.Reader, value any) error {
+	var node yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&node); err != nil {
+		return err
+	}
+	if node.Kind != yaml.DocumentNode || len(node.Content) != 1 {
+		return fmt.Errorf("expected a single YAML document")
+	}
+
+	return setFromYAMLNode(node.Content[0], reflect.ValueOf(value).Elem())
+}
+
+// Returns the YAML node of the value
+func yamlNode(v reflect.Value) (*yaml.Node, error) {
+	if !v.IsValid() {
+		return encodeYAMLNode(nil)
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return encodeYAMLNode(time.Duration(v.Int()).Seconds())
+	case v.Type() == timeType:
+		return encodeYAMLNode(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return encodeYAMLNode(nil)
+		}
+		return yamlNode(v.Elem())
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := yamlFieldName(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+			value, err := yamlNode(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, value)
+		}
+		return node, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return encodeYAMLNode(nil)
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range keys {
+			value, err := yamlNode(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		}
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return encodeYAMLNode(nil)
+		}
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < v.Len(); i++ {
+			value, err := yamlNode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if value.Kind != yaml.ScalarNode {
+				node.Style = 0 // Only lists of scalars fit on a line
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	default:
+		return encodeYAMLNode(v.Interface())
+	}
+}
+
+// Sets the value from the YAML node written by yamlNode
+func setFromYAMLNode(node *yaml.Node, v reflect.Value) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch {
+	case v.Type() == durationType:
+		var seconds float64
+		if err := node.Decode(&seconds); err != nil {
+			return err
+		}
+		v.SetInt(int64(math.Round(seconds * float64(time.Second))))
+		return nil
+	case v.Type() == timeType:
+		return node.Decode(v.Addr().Interface())
+	}
+
+	isNull := node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+	switch v.Kind() {
+	case reflect.Pointer:
+		if isNull {
+			v.SetZero()
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setFromYAMLNode(node, v.Elem())
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("line %d: expected a mapping for %s", node.Line, v.Type())
+		}
+		fields := make(map[string]int, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if name, ok := yamlFieldName(v.Type().Field(i)); ok {
+				fields[name] = i
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			field, known := fields[node.Content[i].Value]
+			if !known {
+				continue
+			}
+			if err := setFromYAMLNode(node.Content[i+1], v.Field(field)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if isNull {
+			v.SetZero()
+			return nil
+		}
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("line %d: expected a mapping for %s", node.Line, v.Type())
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := setFromYAMLNode(node.Content[i+1], value); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(node.Content[i].Value).Convert(v.Type().Key()), value)
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if isNull {
+			v.SetZero()
+			return nil
+		}
+		if node.Kind != yaml.SequenceNode {
+			return fmt.Errorf("line %d: expected a sequence for %s", node.Line, v.Type())
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(v.Type(), len(node.Content), len(node.Content)))
+		} else if len(node.Content) > v.Len() {
+			return fmt.Errorf("line %d: expected at most %d items for %s", node.Line, v.Len(), v.Type())
+		}
+		for i, item := range node.Content {
+			if err := setFromYAMLNode(item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return node.Decode(v.Addr().Interface())
+	}
+}
+
+// Returns the YAML node of a value without durations in it
+func encodeYAMLNode(value any) (*yaml.Node, error) {
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// Returns the name of the field in the JSON encoding, and false for the fields the JSON encoding leaves out
+func yamlFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return name, true
+	}
+}
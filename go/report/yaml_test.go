@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite_YAMLRoundTrips(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {
+			PRsReviewed:              3,
+			AverageCommentsPerReview: 1.5,
+			AverageTimeToFirstReview: 90 * time.Minute,
+			TotalTimeToFirstReview:   4*time.Hour + 30*time.Minute,
+			FirstReviewDate:          time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+			ReviewsByHour:            [24]int{9: 2, 14: 1},
+			CommentCategories:        map[string]int{"question": 2, "nit": 1},
+			LowConfidence:            true,
+		},
+		"1234": {PRsReviewed: 1}, // A login YAML would read as a number unquoted
+	}
+	path := filepath.Join(t.TempDir(), "baseline.yaml")
+	file, err := os.Create(path)
+	assert.NoError(t, err)
+	assert.NoError(t, Write(file, FormatYAML, results, Options{Repo: "owner/repo"}))
+	file.Close()
+
+	read, err := ReadYAML(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, results, read)
+}
+
+func TestWrite_YAMLEnvelope(t *testing.T) {
+	results := map[string]*metrics.ContributorMetrics{"reviewer1": {PRsReviewed: 3, AverageTimeToFirstReview: 90 * time.Minute}}
+	options := Options{
+		Repo:        "owner/repo",
+		DateFrom:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:      time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+		GeneratedAt: time.Date(2025, 2, 1, 8, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, FormatYAML, results, options))
+
+	// The fields of the JSON envelope, in the same order, with the durations in seconds
+	assert.Contains(t, buf.String(), "schemaVersion: "+strconv.Itoa(SchemaVersion)+"\ngeneratedAt: 2025-02-01T08:00:00Z\nrepo: owner/repo\nrange:\n  from: 2025-01-01T00:00:00Z\n  to: 2025-01-31T23:59:59Z\ncontributors:\n  reviewer1:\n    PRsReviewed: 3\n")
+	assert.Contains(t, buf.String(), "    AverageTimeToFirstReview: 5400\n")
+}
+
+func TestReadYAML_RejectsBareMap(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("reviewer1:\n  PRsReviewed: 3\n"), 0o644))
+
+	_, err := ReadYAML(file)
+
+	assert.ErrorContains(t, err, "unsupported schema version 0")
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"src/metrics"
+)
+
+// LoadCaches reads the caches of the incremental runs by owner/repo from the JSON file. A missing file holds no caches.
+func LoadCaches(path string) (map[string]*metrics.Cache, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string]*metrics.Cache), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the cache: %v", err)
+	}
+
+	caches := make(map[string]*metrics.Cache)
+	if err := json.Unmarshal(content, &caches); err != nil {
+		return nil, fmt.Errorf("failed to read the cache from %s: %v", path, err)
+	}
+	return caches, nil
+}
+
+// SaveCaches writes the caches by owner/repo to the JSON file, replacing it only once it's completely written
+func SaveCaches(path string, caches map[string]*metrics.Cache) error {
+	content, err := json.Marshal(caches)
+	if err != nil {
+		return fmt.Errorf("failed to encode the cache: %v", err)
+	}
+
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write the cache: %v", err)
+	}
+	if err := os.Rename(temporary, path); err != nil {
+		return fmt.Errorf("failed to write the cache: %v", err)
+	}
+	return nil
+}
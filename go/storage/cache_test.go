@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"src/gitclient"
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaches_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	// A missing file holds no caches yet
+	caches, err := LoadCaches(path)
+	assert.NoError(t, err)
+	assert.Empty(t, caches)
+
+	createdAt := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	title, login := "PR 1", "contributor1"
+	caches["owner/repo"] = &metrics.Cache{
+		UpdatedAt: time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC),
+		PullRequests: map[int]*metrics.CachedPullRequest{
+			1: {PullRequest: &gitclient.PullRequest{Number: 1, Title: &title, UserLogin: &login, CreatedAt: &createdAt}, LineStats: &gitclient.LineStats{Additions: 10}},
+		},
+	}
+	assert.NoError(t, SaveCaches(path, caches))
+
+	loaded, err := LoadCaches(path)
+	assert.NoError(t, err)
+	assert.Equal(t, caches["owner/repo"].UpdatedAt, loaded["owner/repo"].UpdatedAt)
+	assert.Equal(t, 10, loaded["owner/repo"].PullRequests[1].LineStats.Additions)
+	assert.Equal(t, "PR 1", *loaded["owner/repo"].PullRequests[1].PullRequest.Title)
+}
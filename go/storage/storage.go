@@ -0,0 +1,182 @@
+// Package storage keeps the results of the runs in a SQLite database, so trends can be queried across runs, and the
+// caches of the incremental runs in a JSON file.
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"src/metrics"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, registered as "sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner      TEXT NOT NULL,
+	repo       TEXT NOT NULL,
+	date_from  TEXT NOT NULL,
+	date_to    TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS contributor_metrics (
+	run_id                                 INTEGER NOT NULL REFERENCES runs(id),
+	contributor                            TEXT NOT NULL,
+	prs_reviewed                           INTEGER NOT NULL,
+	total_comments                         INTEGER NOT NULL,
+	average_comments_per_review            REAL NOT NULL,
+	average_time_to_first_review_ns        INTEGER NOT NULL,
+	average_time_to_complete_review_ns     INTEGER NOT NULL,
+	total_lines_reviewed                   INTEGER NOT NULL,
+	average_lines_reviewed                 REAL NOT NULL,
+	percentage_comments_leading_to_changes REAL NOT NULL,
+	reviews_per_active_day                 REAL NOT NULL,
+	approvals_given                        INTEGER NOT NULL,
+	approval_rate                          REAL NOT NULL,
+	instant_approvals                      INTEGER NOT NULL,
+	comment_density                        REAL NOT NULL,
+	sla_compliance_rate                    REAL NOT NULL,
+	distinct_files_commented               INTEGER NOT NULL,
+	first_review_date                      TEXT NOT NULL,
+	days_active_in_range                   INTEGER NOT NULL,
+	PRIMARY KEY (run_id, contributor)
+);`
+
+const metricsColumns = `contributor, prs_reviewed, total_comments, average_comments_per_review, average_time_to_first_review_ns,
+	average_time_to_complete_review_ns, total_lines_reviewed, average_lines_reviewed, percentage_comments_leading_to_changes,
+	reviews_per_active_day, approvals_given, approval_rate, instant_approvals, comment_density, sla_compliance_rate,
+	distinct_files_commented, first_review_date, days_active_in_range`
+
+// Run describes a single calculation of the metrics of a repository
+type Run struct {
+	ID        int64
+	Owner     string
+	Repo      string
+	DateFrom  time.Time
+	DateTo    time.Time
+	CreatedAt time.Time
+}
+
+// Store is a SQLite database holding the runs and their per-contributor metrics
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating the file and the tables if they don't exist yet.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %v", path, err)
+	}
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create tables in %s: %v", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveRun stores the run metadata together with the metrics of every contributor and returns the ID of the run.
+// Only the exported metrics are stored, so loaded results can't be merged with other results.
+func (s *Store) SaveRun(run Run, results map[string]*metrics.ContributorMetrics) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO runs (owner, repo, date_from, date_to, created_at) VALUES (?, ?, ?, ?, ?)",
+		run.Owner, run.Repo, formatTime(run.DateFrom), formatTime(run.DateTo), formatTime(run.CreatedAt))
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for contributor, m := range results {
+		_, err := tx.Exec("INSERT INTO contributor_metrics (run_id, "+metricsColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			id, contributor, m.PRsReviewed, m.TotalComments, m.AverageCommentsPerReview, int64(m.AverageTimeToFirstReview),
+			int64(m.AverageTimeToCompleteReview), m.TotalLinesReviewed, m.AverageLinesReviewed, m.PercentageCommentsLeadingToChanges,
+			m.ReviewsPerActiveDay, m.ApprovalsGiven, m.ApprovalRate, m.InstantApprovals, m.CommentDensity, m.SLAComplianceRate,
+			m.DistinctFilesCommented, formatTime(m.FirstReviewDate), m.DaysActiveInRange)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+// LoadRun reads back the run with the given ID and the metrics of its contributors.
+func (s *Store) LoadRun(id int64) (*Run, map[string]*metrics.ContributorMetrics, error) {
+	run := Run{ID: id}
+	var dateFrom, dateTo, createdAt string
+
+	err := s.db.QueryRow("SELECT owner, repo, date_from, date_to, created_at FROM runs WHERE id = ?", id).
+		Scan(&run.Owner, &run.Repo, &dateFrom, &dateTo, &createdAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if run.DateFrom, err = parseTime(dateFrom); err != nil {
+		return nil, nil, err
+	}
+	if run.DateTo, err = parseTime(dateTo); err != nil {
+		return nil, nil, err
+	}
+	if run.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.db.Query("SELECT "+metricsColumns+" FROM contributor_metrics WHERE run_id = ?", id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	results := make(map[string]*metrics.ContributorMetrics)
+	for rows.Next() {
+		var contributor, firstReviewDate string
+		var timeToFirstReview, timeToCompleteReview int64
+		m := &metrics.ContributorMetrics{}
+
+		err := rows.Scan(&contributor, &m.PRsReviewed, &m.TotalComments, &m.AverageCommentsPerReview, &timeToFirstReview,
+			&timeToCompleteReview, &m.TotalLinesReviewed, &m.AverageLinesReviewed, &m.PercentageCommentsLeadingToChanges,
+			&m.ReviewsPerActiveDay, &m.ApprovalsGiven, &m.ApprovalRate, &m.InstantApprovals, &m.CommentDensity, &m.SLAComplianceRate,
+			&m.DistinctFilesCommented, &firstReviewDate, &m.DaysActiveInRange)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m.AverageTimeToFirstReview = time.Duration(timeToFirstReview)
+		m.AverageTimeToCompleteReview = time.Duration(timeToCompleteReview)
+		if m.FirstReviewDate, err = parseTime(firstReviewDate); err != nil {
+			return nil, nil, err
+		}
+
+		results[contributor] = m
+	}
+
+	return &run, results, rows.Err()
+}
+
+// Times are stored as RFC3339 text so they stay readable in queries
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
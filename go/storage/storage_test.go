@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"src/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_SaveAndLoadRun(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "runs.db"))
+	assert.NoError(t, err)
+	defer store.Close()
+
+	run := Run{
+		Owner:     "owner",
+		Repo:      "repo",
+		DateFrom:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:    time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+		CreatedAt: time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC),
+	}
+	results := map[string]*metrics.ContributorMetrics{
+		"reviewer1": {PRsReviewed: 3, TotalComments: 6, AverageCommentsPerReview: 2, AverageTimeToFirstReview: 90 * time.Minute, ApprovalRate: 0.5,
+			FirstReviewDate: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), DaysActiveInRange: 5},
+		"reviewer2": {PRsReviewed: 1},
+	}
+
+	id, err := store.SaveRun(run, results)
+	assert.NoError(t, err)
+
+	// A second run gets its own ID
+	secondID, err := store.SaveRun(run, map[string]*metrics.ContributorMetrics{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, secondID)
+
+	loadedRun, loadedResults, err := store.LoadRun(id)
+	assert.NoError(t, err)
+
+	run.ID = id
+	assert.Equal(t, run, *loadedRun)
+	assert.Equal(t, results, loadedResults)
+}